@@ -6,17 +6,13 @@ function __fish_goenv_needs_command
   return 1
 end
 
-function __fish_goenv_using_command
+# Completes the arguments of a command like the other shells do, by
+# asking it with the arguments given so far.
+function __fish_goenv_complete_command
   set cmd (commandline -opc)
-  if [ (count $cmd) -gt 1 ]
-    if [ $argv[1] = $cmd[2] ]
-      return 0
-    end
-  end
-  return 1
+  set -e cmd[1]
+  goenv completions $cmd
 end
 
 complete -f -c goenv -n '__fish_goenv_needs_command' -a '(goenv commands)'
-for cmd in (goenv commands)
-  complete -f -c goenv -n "__fish_goenv_using_command $cmd" -a "(goenv completions $cmd)"
-end
+complete -f -c goenv -n 'not __fish_goenv_needs_command' -a '(__fish_goenv_complete_command)'
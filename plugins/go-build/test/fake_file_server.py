@@ -12,7 +12,28 @@ if len(sys.argv) == 2 and len(sys.argv[1]) > 0:
 serve_dir = os.path.join(os.path.dirname(__file__), 'http-definitions')
 os.chdir(serve_dir)
 
-Handler = http.server.SimpleHTTPRequestHandler
+class Handler(http.server.SimpleHTTPRequestHandler):
+  # NOTE: Serves `Range: bytes=<start>-' requests, like release mirrors
+  # do, so resumed downloads can be tested.
+  def send_head(self):
+    range_ = self.headers.get('Range', '')
+    path = self.translate_path(self.path)
+    if not range_.startswith('bytes=') or not range_.endswith('-') or not os.path.isfile(path):
+      return super().send_head()
+    start = int(range_[len('bytes='):-1])
+    size = os.path.getsize(path)
+    if start >= size:
+      self.send_error(416)
+      return None
+    f = open(path, 'rb')
+    f.seek(start)
+    self.send_response(206)
+    self.send_header('Content-Type', self.guess_type(path))
+    self.send_header('Content-Range', 'bytes %d-%d/%d' % (start, size - 1, size))
+    self.send_header('Content-Length', str(size - start))
+    self.end_headers()
+    return f
+
 httpd = socketserver.TCPServer(("localhost", port), Handler)
 print("serving http-definitions from:", serve_dir, "and listening at port:", port)
 httpd.serve_forever()
@@ -9,8 +9,12 @@ export PATH="${project_root}/libexec:$PATH"
   run goenv-help --usage install
   assert_success_out <<OUT
 Usage: goenv install [-f] [-kvpq] <version>|latest|unstable
+       goenv install [-f] [-kvq] [--jobs <n>] <version> <version>...
        goenv install [-f] [-kvpq] <definition-file>
-       goenv install -l|--list
+       goenv install [-kvpq] gotip [--update]
+       goenv install [-f] [-kvpq] -i|--interactive
+       goenv install --from-file [--prune] <file>
+       goenv install -l|--list [--stable|--unstable|--archived]
        goenv install --version
 OUT
 }
@@ -20,14 +24,33 @@ OUT
   run goenv-install --complete
   assert_success_out <<OUT
 --list
+--interactive
+--stable
+--unstable
+--archived
 --force
 --skip-existing
+--dry-run
+--json
+--queue
+--events
+--from-file
+--jobs
+--mirror
+--require-verification
+--static-ok
+--build
+--update
 --keep
 --patch
 --verbose
 --version
 --debug
 --quiet
+latest
+latest:stable
+latest:rc
+gotip
 1.0.0
 1.2.0
 1.2.2
@@ -40,24 +63,65 @@ OUT
   run goenv-install -h
   assert_success_out <<'OUT'
 Usage: goenv install [-f] [-kvpq] <version>|latest|unstable
+       goenv install [-f] [-kvq] [--jobs <n>] <version> <version>...
        goenv install [-f] [-kvpq] <definition-file>
-       goenv install -l|--list
+       goenv install [-kvpq] gotip [--update]
+       goenv install [-f] [-kvpq] -i|--interactive
+       goenv install --from-file [--prune] <file>
+       goenv install -l|--list [--stable|--unstable|--archived]
        goenv install --version
 
   -l/--list          List all available versions
+  -i/--interactive   Pick the version to install from the available ones,
+                     newest first, see `goenv help prompt'
+  --stable           List only stable releases
+  --unstable         List only betas and release candidates
+  --archived         List only releases of minor versions that reached
+                     their end of life
   -f/--force         Install even if the version appears to be installed already
   -s/--skip-existing Skip if the version appears to be installed already
+  -n/--dry-run       Print the install plan (URL, checksum, target directory,
+                     disk space, tools) without downloading or installing anything
+  --json             Print the install plan as JSON (implies --dry-run)
+  --queue            Queue the version to be installed later by `goenv queue run'
+  --events           Print install events as JSON lines on stdout, e.g. for
+                     GUI front-ends, and everything else on stderr
+  --from-file        Install the versions listed in a `.go-versions' or
+                     `goenv.yaml' file, see `goenv help sync'
+  --jobs <n>         Install up to <n> of several versions at a time
+                     (defaults to `GOENV_INSTALL_JOBS', or 4)
+  --mirror <dir-or-url>
+                     Install from the archives in a directory or at a URL
+                     instead of go.dev (defaults to `GOENV_MIRROR')
+  --require-verification
+                     Fail unless the archive matches a SHA-256 checksum
+                     (defaults to `GOENV_REQUIRE_VERIFICATION')
+  --static-ok        Install an official release on a musl system, e.g.
+                     Alpine, although it's built for glibc
+  --build, --build-from-source
+                     Build the version from source with make.bash, e.g. on
+                     platforms without official releases
+  --update           Update tip to the latest commit, see below
 
   go-build options:
 
   -k/--keep          Keep source tree in $GOENV_BUILD_ROOT after installation
                      (defaults to $GOENV_ROOT/sources)
-  -p/--patch         Apply a patch from stdin before building
+  -p/--patch         Apply a patch from stdin before building, with --build
   -v/--verbose       Verbose mode: print compilation status to stdout
-  -q/--quiet         Disable Progress Bar
+  -q/--quiet         Disable Progress Bar, and print a summary line
+                     of each download instead
   --version          Show version of go-build
   -g/--debug         Build a debug version
 
+`gotip' clones the development branch of Go and builds it with
+make.bash, bootstrapped by the newest installed version, as the version
+`tip'. `goenv install gotip --update' builds the latest commit again.
+
+Under the Go release policy, each minor version receives security fixes
+until two newer minor versions are released. On a terminal, the list
+shows this for the newest release of each minor version.
+
 For detailed information on installing Go versions with
 go-build, including a list of environment variables for adjusting
 compilation, see: https://github.com/go-nv/goenv#readme
@@ -68,24 +132,65 @@ OUT
   run goenv-install --help
   assert_success_out <<'OUT'
 Usage: goenv install [-f] [-kvpq] <version>|latest|unstable
+       goenv install [-f] [-kvq] [--jobs <n>] <version> <version>...
        goenv install [-f] [-kvpq] <definition-file>
-       goenv install -l|--list
+       goenv install [-kvpq] gotip [--update]
+       goenv install [-f] [-kvpq] -i|--interactive
+       goenv install --from-file [--prune] <file>
+       goenv install -l|--list [--stable|--unstable|--archived]
        goenv install --version
 
   -l/--list          List all available versions
+  -i/--interactive   Pick the version to install from the available ones,
+                     newest first, see `goenv help prompt'
+  --stable           List only stable releases
+  --unstable         List only betas and release candidates
+  --archived         List only releases of minor versions that reached
+                     their end of life
   -f/--force         Install even if the version appears to be installed already
   -s/--skip-existing Skip if the version appears to be installed already
+  -n/--dry-run       Print the install plan (URL, checksum, target directory,
+                     disk space, tools) without downloading or installing anything
+  --json             Print the install plan as JSON (implies --dry-run)
+  --queue            Queue the version to be installed later by `goenv queue run'
+  --events           Print install events as JSON lines on stdout, e.g. for
+                     GUI front-ends, and everything else on stderr
+  --from-file        Install the versions listed in a `.go-versions' or
+                     `goenv.yaml' file, see `goenv help sync'
+  --jobs <n>         Install up to <n> of several versions at a time
+                     (defaults to `GOENV_INSTALL_JOBS', or 4)
+  --mirror <dir-or-url>
+                     Install from the archives in a directory or at a URL
+                     instead of go.dev (defaults to `GOENV_MIRROR')
+  --require-verification
+                     Fail unless the archive matches a SHA-256 checksum
+                     (defaults to `GOENV_REQUIRE_VERIFICATION')
+  --static-ok        Install an official release on a musl system, e.g.
+                     Alpine, although it's built for glibc
+  --build, --build-from-source
+                     Build the version from source with make.bash, e.g. on
+                     platforms without official releases
+  --update           Update tip to the latest commit, see below
 
   go-build options:
 
   -k/--keep          Keep source tree in $GOENV_BUILD_ROOT after installation
                      (defaults to $GOENV_ROOT/sources)
-  -p/--patch         Apply a patch from stdin before building
+  -p/--patch         Apply a patch from stdin before building, with --build
   -v/--verbose       Verbose mode: print compilation status to stdout
-  -q/--quiet         Disable Progress Bar
+  -q/--quiet         Disable Progress Bar, and print a summary line
+                     of each download instead
   --version          Show version of go-build
   -g/--debug         Build a debug version
 
+`gotip' clones the development branch of Go and builds it with
+make.bash, bootstrapped by the newest installed version, as the version
+`tip'. `goenv install gotip --update' builds the latest commit again.
+
+Under the Go release policy, each minor version receives security fixes
+until two newer minor versions are released. On a terminal, the list
+shows this for the newest release of each minor version.
+
 For detailed information on installing Go versions with
 go-build, including a list of environment variables for adjusting
 compilation, see: https://github.com/go-nv/goenv#readme
@@ -96,24 +201,65 @@ OUT
   run goenv-install
   assert_failure_out <<'OUT'
 Usage: goenv install [-f] [-kvpq] <version>|latest|unstable
+       goenv install [-f] [-kvq] [--jobs <n>] <version> <version>...
        goenv install [-f] [-kvpq] <definition-file>
-       goenv install -l|--list
+       goenv install [-kvpq] gotip [--update]
+       goenv install [-f] [-kvpq] -i|--interactive
+       goenv install --from-file [--prune] <file>
+       goenv install -l|--list [--stable|--unstable|--archived]
        goenv install --version
 
   -l/--list          List all available versions
+  -i/--interactive   Pick the version to install from the available ones,
+                     newest first, see `goenv help prompt'
+  --stable           List only stable releases
+  --unstable         List only betas and release candidates
+  --archived         List only releases of minor versions that reached
+                     their end of life
   -f/--force         Install even if the version appears to be installed already
   -s/--skip-existing Skip if the version appears to be installed already
+  -n/--dry-run       Print the install plan (URL, checksum, target directory,
+                     disk space, tools) without downloading or installing anything
+  --json             Print the install plan as JSON (implies --dry-run)
+  --queue            Queue the version to be installed later by `goenv queue run'
+  --events           Print install events as JSON lines on stdout, e.g. for
+                     GUI front-ends, and everything else on stderr
+  --from-file        Install the versions listed in a `.go-versions' or
+                     `goenv.yaml' file, see `goenv help sync'
+  --jobs <n>         Install up to <n> of several versions at a time
+                     (defaults to `GOENV_INSTALL_JOBS', or 4)
+  --mirror <dir-or-url>
+                     Install from the archives in a directory or at a URL
+                     instead of go.dev (defaults to `GOENV_MIRROR')
+  --require-verification
+                     Fail unless the archive matches a SHA-256 checksum
+                     (defaults to `GOENV_REQUIRE_VERIFICATION')
+  --static-ok        Install an official release on a musl system, e.g.
+                     Alpine, although it's built for glibc
+  --build, --build-from-source
+                     Build the version from source with make.bash, e.g. on
+                     platforms without official releases
+  --update           Update tip to the latest commit, see below
 
   go-build options:
 
   -k/--keep          Keep source tree in $GOENV_BUILD_ROOT after installation
                      (defaults to $GOENV_ROOT/sources)
-  -p/--patch         Apply a patch from stdin before building
+  -p/--patch         Apply a patch from stdin before building, with --build
   -v/--verbose       Verbose mode: print compilation status to stdout
-  -q/--quiet         Disable Progress Bar
+  -q/--quiet         Disable Progress Bar, and print a summary line
+                     of each download instead
   --version          Show version of go-build
   -g/--debug         Build a debug version
 
+`gotip' clones the development branch of Go and builds it with
+make.bash, bootstrapped by the newest installed version, as the version
+`tip'. `goenv install gotip --update' builds the latest commit again.
+
+Under the Go release policy, each minor version receives security fixes
+until two newer minor versions are released. On a terminal, the list
+shows this for the newest release of each minor version.
+
 For detailed information on installing Go versions with
 go-build, including a list of environment variables for adjusting
 compilation, see: https://github.com/go-nv/goenv#readme
@@ -124,24 +270,65 @@ OUT
   run goenv-install -f
   assert_failure_out <<'OUT'
 Usage: goenv install [-f] [-kvpq] <version>|latest|unstable
+       goenv install [-f] [-kvq] [--jobs <n>] <version> <version>...
        goenv install [-f] [-kvpq] <definition-file>
-       goenv install -l|--list
+       goenv install [-kvpq] gotip [--update]
+       goenv install [-f] [-kvpq] -i|--interactive
+       goenv install --from-file [--prune] <file>
+       goenv install -l|--list [--stable|--unstable|--archived]
        goenv install --version
 
   -l/--list          List all available versions
+  -i/--interactive   Pick the version to install from the available ones,
+                     newest first, see `goenv help prompt'
+  --stable           List only stable releases
+  --unstable         List only betas and release candidates
+  --archived         List only releases of minor versions that reached
+                     their end of life
   -f/--force         Install even if the version appears to be installed already
   -s/--skip-existing Skip if the version appears to be installed already
+  -n/--dry-run       Print the install plan (URL, checksum, target directory,
+                     disk space, tools) without downloading or installing anything
+  --json             Print the install plan as JSON (implies --dry-run)
+  --queue            Queue the version to be installed later by `goenv queue run'
+  --events           Print install events as JSON lines on stdout, e.g. for
+                     GUI front-ends, and everything else on stderr
+  --from-file        Install the versions listed in a `.go-versions' or
+                     `goenv.yaml' file, see `goenv help sync'
+  --jobs <n>         Install up to <n> of several versions at a time
+                     (defaults to `GOENV_INSTALL_JOBS', or 4)
+  --mirror <dir-or-url>
+                     Install from the archives in a directory or at a URL
+                     instead of go.dev (defaults to `GOENV_MIRROR')
+  --require-verification
+                     Fail unless the archive matches a SHA-256 checksum
+                     (defaults to `GOENV_REQUIRE_VERIFICATION')
+  --static-ok        Install an official release on a musl system, e.g.
+                     Alpine, although it's built for glibc
+  --build, --build-from-source
+                     Build the version from source with make.bash, e.g. on
+                     platforms without official releases
+  --update           Update tip to the latest commit, see below
 
   go-build options:
 
   -k/--keep          Keep source tree in $GOENV_BUILD_ROOT after installation
                      (defaults to $GOENV_ROOT/sources)
-  -p/--patch         Apply a patch from stdin before building
+  -p/--patch         Apply a patch from stdin before building, with --build
   -v/--verbose       Verbose mode: print compilation status to stdout
-  -q/--quiet         Disable Progress Bar
+  -q/--quiet         Disable Progress Bar, and print a summary line
+                     of each download instead
   --version          Show version of go-build
   -g/--debug         Build a debug version
 
+`gotip' clones the development branch of Go and builds it with
+make.bash, bootstrapped by the newest installed version, as the version
+`tip'. `goenv install gotip --update' builds the latest commit again.
+
+Under the Go release policy, each minor version receives security fixes
+until two newer minor versions are released. On a terminal, the list
+shows this for the newest release of each minor version.
+
 For detailed information on installing Go versions with
 go-build, including a list of environment variables for adjusting
 compilation, see: https://github.com/go-nv/goenv#readme
@@ -152,24 +339,65 @@ OUT
   run goenv-install --force
   assert_failure_out <<'OUT'
 Usage: goenv install [-f] [-kvpq] <version>|latest|unstable
+       goenv install [-f] [-kvq] [--jobs <n>] <version> <version>...
        goenv install [-f] [-kvpq] <definition-file>
-       goenv install -l|--list
+       goenv install [-kvpq] gotip [--update]
+       goenv install [-f] [-kvpq] -i|--interactive
+       goenv install --from-file [--prune] <file>
+       goenv install -l|--list [--stable|--unstable|--archived]
        goenv install --version
 
   -l/--list          List all available versions
+  -i/--interactive   Pick the version to install from the available ones,
+                     newest first, see `goenv help prompt'
+  --stable           List only stable releases
+  --unstable         List only betas and release candidates
+  --archived         List only releases of minor versions that reached
+                     their end of life
   -f/--force         Install even if the version appears to be installed already
   -s/--skip-existing Skip if the version appears to be installed already
+  -n/--dry-run       Print the install plan (URL, checksum, target directory,
+                     disk space, tools) without downloading or installing anything
+  --json             Print the install plan as JSON (implies --dry-run)
+  --queue            Queue the version to be installed later by `goenv queue run'
+  --events           Print install events as JSON lines on stdout, e.g. for
+                     GUI front-ends, and everything else on stderr
+  --from-file        Install the versions listed in a `.go-versions' or
+                     `goenv.yaml' file, see `goenv help sync'
+  --jobs <n>         Install up to <n> of several versions at a time
+                     (defaults to `GOENV_INSTALL_JOBS', or 4)
+  --mirror <dir-or-url>
+                     Install from the archives in a directory or at a URL
+                     instead of go.dev (defaults to `GOENV_MIRROR')
+  --require-verification
+                     Fail unless the archive matches a SHA-256 checksum
+                     (defaults to `GOENV_REQUIRE_VERIFICATION')
+  --static-ok        Install an official release on a musl system, e.g.
+                     Alpine, although it's built for glibc
+  --build, --build-from-source
+                     Build the version from source with make.bash, e.g. on
+                     platforms without official releases
+  --update           Update tip to the latest commit, see below
 
   go-build options:
 
   -k/--keep          Keep source tree in $GOENV_BUILD_ROOT after installation
                      (defaults to $GOENV_ROOT/sources)
-  -p/--patch         Apply a patch from stdin before building
+  -p/--patch         Apply a patch from stdin before building, with --build
   -v/--verbose       Verbose mode: print compilation status to stdout
-  -q/--quiet         Disable Progress Bar
+  -q/--quiet         Disable Progress Bar, and print a summary line
+                     of each download instead
   --version          Show version of go-build
   -g/--debug         Build a debug version
 
+`gotip' clones the development branch of Go and builds it with
+make.bash, bootstrapped by the newest installed version, as the version
+`tip'. `goenv install gotip --update' builds the latest commit again.
+
+Under the Go release policy, each minor version receives security fixes
+until two newer minor versions are released. On a terminal, the list
+shows this for the newest release of each minor version.
+
 For detailed information on installing Go versions with
 go-build, including a list of environment variables for adjusting
 compilation, see: https://github.com/go-nv/goenv#readme
@@ -180,24 +408,65 @@ OUT
   run goenv-install -f -
   assert_failure_out <<'OUT'
 Usage: goenv install [-f] [-kvpq] <version>|latest|unstable
+       goenv install [-f] [-kvq] [--jobs <n>] <version> <version>...
        goenv install [-f] [-kvpq] <definition-file>
-       goenv install -l|--list
+       goenv install [-kvpq] gotip [--update]
+       goenv install [-f] [-kvpq] -i|--interactive
+       goenv install --from-file [--prune] <file>
+       goenv install -l|--list [--stable|--unstable|--archived]
        goenv install --version
 
   -l/--list          List all available versions
+  -i/--interactive   Pick the version to install from the available ones,
+                     newest first, see `goenv help prompt'
+  --stable           List only stable releases
+  --unstable         List only betas and release candidates
+  --archived         List only releases of minor versions that reached
+                     their end of life
   -f/--force         Install even if the version appears to be installed already
   -s/--skip-existing Skip if the version appears to be installed already
+  -n/--dry-run       Print the install plan (URL, checksum, target directory,
+                     disk space, tools) without downloading or installing anything
+  --json             Print the install plan as JSON (implies --dry-run)
+  --queue            Queue the version to be installed later by `goenv queue run'
+  --events           Print install events as JSON lines on stdout, e.g. for
+                     GUI front-ends, and everything else on stderr
+  --from-file        Install the versions listed in a `.go-versions' or
+                     `goenv.yaml' file, see `goenv help sync'
+  --jobs <n>         Install up to <n> of several versions at a time
+                     (defaults to `GOENV_INSTALL_JOBS', or 4)
+  --mirror <dir-or-url>
+                     Install from the archives in a directory or at a URL
+                     instead of go.dev (defaults to `GOENV_MIRROR')
+  --require-verification
+                     Fail unless the archive matches a SHA-256 checksum
+                     (defaults to `GOENV_REQUIRE_VERIFICATION')
+  --static-ok        Install an official release on a musl system, e.g.
+                     Alpine, although it's built for glibc
+  --build, --build-from-source
+                     Build the version from source with make.bash, e.g. on
+                     platforms without official releases
+  --update           Update tip to the latest commit, see below
 
   go-build options:
 
   -k/--keep          Keep source tree in $GOENV_BUILD_ROOT after installation
                      (defaults to $GOENV_ROOT/sources)
-  -p/--patch         Apply a patch from stdin before building
+  -p/--patch         Apply a patch from stdin before building, with --build
   -v/--verbose       Verbose mode: print compilation status to stdout
-  -q/--quiet         Disable Progress Bar
+  -q/--quiet         Disable Progress Bar, and print a summary line
+                     of each download instead
   --version          Show version of go-build
   -g/--debug         Build a debug version
 
+`gotip' clones the development branch of Go and builds it with
+make.bash, bootstrapped by the newest installed version, as the version
+`tip'. `goenv install gotip --update' builds the latest commit again.
+
+Under the Go release policy, each minor version receives security fixes
+until two newer minor versions are released. On a terminal, the list
+shows this for the newest release of each minor version.
+
 For detailed information on installing Go versions with
 go-build, including a list of environment variables for adjusting
 compilation, see: https://github.com/go-nv/goenv#readme
@@ -208,24 +477,65 @@ OUT
   run goenv-install --force
   assert_failure_out <<'OUT'
 Usage: goenv install [-f] [-kvpq] <version>|latest|unstable
+       goenv install [-f] [-kvq] [--jobs <n>] <version> <version>...
        goenv install [-f] [-kvpq] <definition-file>
-       goenv install -l|--list
+       goenv install [-kvpq] gotip [--update]
+       goenv install [-f] [-kvpq] -i|--interactive
+       goenv install --from-file [--prune] <file>
+       goenv install -l|--list [--stable|--unstable|--archived]
        goenv install --version
 
   -l/--list          List all available versions
+  -i/--interactive   Pick the version to install from the available ones,
+                     newest first, see `goenv help prompt'
+  --stable           List only stable releases
+  --unstable         List only betas and release candidates
+  --archived         List only releases of minor versions that reached
+                     their end of life
   -f/--force         Install even if the version appears to be installed already
   -s/--skip-existing Skip if the version appears to be installed already
+  -n/--dry-run       Print the install plan (URL, checksum, target directory,
+                     disk space, tools) without downloading or installing anything
+  --json             Print the install plan as JSON (implies --dry-run)
+  --queue            Queue the version to be installed later by `goenv queue run'
+  --events           Print install events as JSON lines on stdout, e.g. for
+                     GUI front-ends, and everything else on stderr
+  --from-file        Install the versions listed in a `.go-versions' or
+                     `goenv.yaml' file, see `goenv help sync'
+  --jobs <n>         Install up to <n> of several versions at a time
+                     (defaults to `GOENV_INSTALL_JOBS', or 4)
+  --mirror <dir-or-url>
+                     Install from the archives in a directory or at a URL
+                     instead of go.dev (defaults to `GOENV_MIRROR')
+  --require-verification
+                     Fail unless the archive matches a SHA-256 checksum
+                     (defaults to `GOENV_REQUIRE_VERIFICATION')
+  --static-ok        Install an official release on a musl system, e.g.
+                     Alpine, although it's built for glibc
+  --build, --build-from-source
+                     Build the version from source with make.bash, e.g. on
+                     platforms without official releases
+  --update           Update tip to the latest commit, see below
 
   go-build options:
 
   -k/--keep          Keep source tree in $GOENV_BUILD_ROOT after installation
                      (defaults to $GOENV_ROOT/sources)
-  -p/--patch         Apply a patch from stdin before building
+  -p/--patch         Apply a patch from stdin before building, with --build
   -v/--verbose       Verbose mode: print compilation status to stdout
-  -q/--quiet         Disable Progress Bar
+  -q/--quiet         Disable Progress Bar, and print a summary line
+                     of each download instead
   --version          Show version of go-build
   -g/--debug         Build a debug version
 
+`gotip' clones the development branch of Go and builds it with
+make.bash, bootstrapped by the newest installed version, as the version
+`tip'. `goenv install gotip --update' builds the latest commit again.
+
+Under the Go release policy, each minor version receives security fixes
+until two newer minor versions are released. On a terminal, the list
+shows this for the newest release of each minor version.
+
 For detailed information on installing Go versions with
 go-build, including a list of environment variables for adjusting
 compilation, see: https://github.com/go-nv/goenv#readme
@@ -304,6 +614,57 @@ OUT
   unset USE_FAKE_DEFINITIONS
 }
 
+@test "lists only the stable, unstable or archived releases" {
+  export USE_FAKE_DEFINITIONS=true
+  export GO_BUILD_DEFINITIONS="${BATS_TMPDIR}/definitions"
+  mkdir -p "$GO_BUILD_DEFINITIONS"
+  touch "${GO_BUILD_DEFINITIONS}/1.3.0" "${GO_BUILD_DEFINITIONS}/1.4rc1"
+
+  run goenv-install --list --stable
+  assert_success_out <<OUT
+Available versions:
+  1.0.0
+  1.2.0
+  1.2.2
+  1.3.0
+OUT
+
+  run goenv-install --unstable -l
+  assert_success_out <<OUT
+Available versions:
+  1.3beta1
+  1.4rc1
+OUT
+
+  run goenv-install --list --archived
+  assert_success_out <<OUT
+Available versions:
+  1.0.0
+OUT
+
+  rm -rf "$GO_BUILD_DEFINITIONS"
+  unset USE_FAKE_DEFINITIONS GO_BUILD_DEFINITIONS
+}
+
+@test "shows which minor versions are supported on a terminal" {
+  export USE_FAKE_DEFINITIONS=true
+  export GO_BUILD_DEFINITIONS="${BATS_TMPDIR}/definitions"
+  mkdir -p "$GO_BUILD_DEFINITIONS"
+  touch "${GO_BUILD_DEFINITIONS}/1.3.0"
+
+  run script -qec "goenv-install --list --stable" /dev/null
+  assert_success
+  assert_equal "$(printf "%s\r\n" "Available versions:" "  1.0.0  (end of life)" "  1.2.0" "  1.2.2  (supported until go 1.4 is released)" "  1.3.0  (supported until go 1.5 is released)")" "$output"
+
+  rm -rf "$GO_BUILD_DEFINITIONS"
+  unset USE_FAKE_DEFINITIONS GO_BUILD_DEFINITIONS
+}
+
+@test "fails with a list filter without '--list'" {
+  run goenv-install --stable 1.2.2
+  assert_failure
+}
+
 @test "prints go-build version when '--version' argument is given" {
   base_dir=$(echo $(dirname -- "$0") | sed -E 's/goenv(\/[0-9]+\.[0-9]+\.[0-9]+|\/goenv)?.+/goenv\/\1/i')
   base_dir=$(echo $base_dir | sed -E 's/\/$//')
@@ -379,6 +740,7 @@ OUT
   export USE_FAKE_DEFINITIONS=true
 
   run goenv-install -q latest
+  output="$(echo "$output" | sed 's/ in [0-9]*s)$/ in 0s)/')"
 
   unset USE_FAKE_DEFINITIONS
 
@@ -396,6 +758,7 @@ OUT
 Installing latest version ${LATEST_VERSION}...
 Downloading ${LATEST_VERSION}.tar.gz...
 -> http://localhost:8090/${LATEST_VERSION}/${LATEST_VERSION}.tar.gz
+Downloaded ${LATEST_VERSION}.tar.gz (190 B in 0s)
 Installing Go Linux${arch}64bit ${LATEST_VERSION}...
 Installed Go Linux${arch}64bit ${LATEST_VERSION} to ${GOENV_ROOT}/versions/${LATEST_VERSION}
 
@@ -406,6 +769,7 @@ OUT
 Installing latest version ${LATEST_VERSION}...
 Downloading ${LATEST_VERSION}.tar.gz...
 -> http://localhost:8090/${LATEST_VERSION}/${LATEST_VERSION}.tar.gz
+Downloaded ${LATEST_VERSION}.tar.gz (190 B in 0s)
 Installing Go Darwin 10.8${arch}${LATEST_VERSION}...
 Installed Go Darwin 10.8${arch}${LATEST_VERSION} to ${GOENV_ROOT}/versions/${LATEST_VERSION}
 
@@ -434,6 +798,7 @@ OUT
   export USE_FAKE_DEFINITIONS=true
 
   run goenv-install --quiet latest
+  output="$(echo "$output" | sed 's/ in [0-9]*s)$/ in 0s)/')"
 
   unset USE_FAKE_DEFINITIONS
   export DISABLE_PROGRESS_BAR=true
@@ -450,6 +815,7 @@ OUT
 Installing latest version ${LATEST_VERSION}...
 Downloading ${LATEST_VERSION}.tar.gz...
 -> http://localhost:8090/${LATEST_VERSION}/${LATEST_VERSION}.tar.gz
+Downloaded ${LATEST_VERSION}.tar.gz (190 B in 0s)
 Installing Go Linux${arch}64bit ${LATEST_VERSION}...
 Installed Go Linux${arch}64bit ${LATEST_VERSION} to ${GOENV_ROOT}/versions/${LATEST_VERSION}
 
@@ -460,6 +826,7 @@ OUT
 Installing latest version ${LATEST_VERSION}...
 Downloading ${LATEST_VERSION}.tar.gz...
 -> http://localhost:8090/${LATEST_VERSION}/${LATEST_VERSION}.tar.gz
+Downloaded ${LATEST_VERSION}.tar.gz (190 B in 0s)
 Installing Go Darwin 10.8${arch}${LATEST_VERSION}...
 Installed Go Darwin 10.8${arch}${LATEST_VERSION} to ${GOENV_ROOT}/versions/${LATEST_VERSION}
 
@@ -790,3 +1157,729 @@ OUT
   assert [ -f "${GOENV_ROOT}/versions/1.2.2/bin/go" ]
   run cat "${GOENV_ROOT}/versions/1.2.2/bin/go"
 }
+
+@test "prints the install plan without installing when '--dry-run' is given" {
+  export USE_FAKE_DEFINITIONS=true
+
+  run goenv-install --dry-run 1.2.2
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  assert_line 1 "  url:     http://localhost:8090/1.2.2/1.2.2.tar.gz"
+  assert_line 2 "  sha256:  d7518d03fc9d7ac1d32c49358594ff6517712c3d3de4f80ebaa3229361f38937"
+  assert_line 3 "  prefix:  ${GOENV_ROOT}/versions/1.2.2"
+  assert [ ! -d "${GOENV_ROOT}/versions/1.2.2" ]
+}
+
+@test "prints the disk space and the tools of the selected version in the install plan" {
+  export USE_FAKE_DEFINITIONS=true
+  mkdir -p "${GOENV_ROOT}/versions/1.2.0/bin"
+  touch "${GOENV_ROOT}/versions/1.2.0/bin/go"
+  chmod +x "${GOENV_ROOT}/versions/1.2.0/bin/go"
+  dd if=/dev/zero of="${GOENV_ROOT}/versions/1.2.0/pkg" bs=1024 count=3072 2>/dev/null
+  mkdir -p "${GOENV_TEST_DIR}/gopath/1.2.0/bin"
+  touch "${GOENV_TEST_DIR}/gopath/1.2.0/bin/gopls" "${GOENV_TEST_DIR}/gopath/1.2.0/bin/stringer"
+  chmod +x "${GOENV_TEST_DIR}/gopath/1.2.0/bin/"*
+
+  GOENV_VERSION=1.2.0 GOENV_GOPATH_PREFIX="${GOENV_TEST_DIR}/gopath" run goenv-install --dry-run 1.2.2
+  assert_success
+  assert_line 4 "  disk:    about +3 MB, estimated from 1.2.0"
+  assert_line 5 "  tools:   gopls stringer (installed for 1.2.0, run 'goenv tools sync --from-version 1.2.0' to install them)"
+
+  GOENV_VERSION=1.2.0 GOENV_GOPATH_PREFIX="${GOENV_TEST_DIR}/gopath" run goenv-install --json 1.2.2
+  assert_success
+  [[ "${lines[0]}" == *'"exists":false,"disk_delta_kb":30'??',"tools_from":"1.2.0","tools":["gopls","stringer"]}' ]]
+
+  unset USE_FAKE_DEFINITIONS
+}
+
+@test "installs the version picked with '--interactive', newest first" {
+  export USE_FAKE_DEFINITIONS=true
+
+  run goenv-install -i --dry-run <<< "2"
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  assert_line 0 "  1) 1.3beta1"
+  assert_line 1 "  2) 1.2.2"
+  assert_line 4 "goenv: install which version? 2"
+  assert_line 6 "  url:     http://localhost:8090/1.2.2/1.2.2.tar.gz"
+  assert [ ! -d "${GOENV_ROOT}/versions/1.2.2" ]
+}
+
+@test "fails when '--interactive' is given with a version" {
+  run goenv-install -i 1.2.2
+  assert_failure
+  assert_line 0 "Usage: goenv install [-f] [-kvpq] <version>|latest|unstable"
+}
+
+@test "prints the install plan as JSON when '--json' is given" {
+  export USE_FAKE_DEFINITIONS=true
+  mkdir -p "${GOENV_ROOT}/versions/1.2.2/bin"
+
+  run goenv-install --json latest
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  assert_line 0 "Installing latest version 1.2.2..."
+  json="${lines[1]}"
+  assert_equal '{"name":"Go ' "${json:0:12}"
+  assert_equal "\"url\":\"http://localhost:8090/1.2.2/1.2.2.tar.gz\",\"mirror_url\":\"\",\"sha256\":\"d7518d03fc9d7ac1d32c49358594ff6517712c3d3de4f80ebaa3229361f38937\",\"prefix\":\"${GOENV_ROOT}/versions/1.2.2\",\"exists\":true,\"disk_delta_kb\":null,\"tools_from\":\"\",\"tools\":[]}" "${json#*\",}"
+}
+
+@test "escapes the prefix in the install plan JSON" {
+  export USE_FAKE_DEFINITIONS=true
+
+  run go-build --json 1.2.2 "${GOENV_TEST_DIR}/say \"go\"\\1.2.2"
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  [[ "$output" == *'"prefix":"'"${GOENV_TEST_DIR}"'/say \"go\"\\1.2.2",'* ]]
+}
+
+@test "does not run install hooks when '--dry-run' is given" {
+  export USE_FAKE_DEFINITIONS=true
+  create_hook install hello.bash <<SH
+before_install 'echo before: \$PREFIX'
+SH
+
+  run goenv-install -n 1.2.2
+
+  remove_hook install hello.bash
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  refute_line "before: ${GOENV_ROOT}/versions/1.2.2"
+}
+
+@test "fails when '--dry-run' is given and the version is not a known version definition" {
+  run goenv-install --dry-run 1.2.3
+
+  assert_failure_out <<OUT
+go-build: definition not found: 1.2.3
+See all available versions with 'goenv install --list'.
+OUT
+}
+
+@test "queues the version instead of installing it when '--queue' is given" {
+  export USE_FAKE_DEFINITIONS=true
+
+  run goenv-install --queue 1.2
+  run goenv-install --queue 1.2.2
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success "goenv: queued 1.2.2 for installation, run 'goenv queue run' to install it"
+  assert_equal "1.2.2" "$(cat "${GOENV_ROOT}/queue")"
+  assert [ ! -d "${GOENV_ROOT}/versions/1.2.2" ]
+}
+
+@test "does not queue a version that is already installed when '--queue' is given" {
+  mkdir -p "${GOENV_ROOT}/versions/1.2.2/bin"
+
+  run goenv-install --queue 1.2.2
+
+  assert_success "goenv: 1.2.2 is already installed"
+  assert [ ! -e "${GOENV_ROOT}/queue" ]
+}
+
+@test "prints the install plan for a version of a distribution configured in 'GOENV_ROOT/distributions'" {
+  mkdir -p "${GOENV_ROOT}/distributions"
+  cat > "${GOENV_ROOT}/distributions/acme" <<SH
+DISTRIBUTION_NAME="Acme Go"
+DISTRIBUTION_URL="http://localhost:8090/{version}/{version}.tar.gz"
+DISTRIBUTION_RELEASES_URL="http://localhost:8090/releases.json"
+SH
+
+  run goenv-install --dry-run acme-1.2.0
+
+  assert_success_out <<OUT
+Would install Acme Go 1.2.0
+  url:     http://localhost:8090/1.2.0/1.2.0.tar.gz
+  sha256:  d7518d03fc9d7ac1d32c49358594ff6517712c3d3de4f80ebaa3229361f38937
+  prefix:  ${GOENV_ROOT}/versions/acme-1.2.0
+  disk:    unknown, no installed version to estimate it from
+  tools:   none
+OUT
+}
+
+@test "installs a version of a distribution under its namespaced name" {
+  mkdir -p "${GOENV_ROOT}/distributions"
+  cat > "${GOENV_ROOT}/distributions/acme" <<SH
+DISTRIBUTION_URL="http://localhost:8090/{version}/{version}.tar.gz"
+SH
+  stub goenv-hooks "install : true"
+  stub goenv-rehash "true"
+
+  run goenv-install acme-1.2.0
+
+  assert_success
+  assert_line 0 "Downloading 1.2.0.tar.gz..."
+  assert_line 1 "-> http://localhost:8090/1.2.0/1.2.0.tar.gz"
+  assert_line 2 "Installing acme 1.2.0..."
+  assert_line 3 "Installed acme 1.2.0 to ${GOENV_ROOT}/versions/acme-1.2.0"
+  assert [ -f "${GOENV_ROOT}/versions/acme-1.2.0/bin/go" ]
+}
+
+@test "fails when a distribution has no release for the version" {
+  mkdir -p "${GOENV_ROOT}/distributions"
+  cat > "${GOENV_ROOT}/distributions/acme" <<SH
+DISTRIBUTION_URL="http://localhost:8090/{version}/{version}.tar.gz"
+DISTRIBUTION_RELEASES_URL="http://localhost:8090/releases.json"
+SH
+
+  run goenv-install acme-9.9.9
+
+  assert_failure "go-build: distribution 'acme' has no release 9.9.9.tar.gz"
+  assert [ ! -d "${GOENV_ROOT}/versions/acme-9.9.9" ]
+}
+
+@test "warns about multiple goenv installations in PATH" {
+  export USE_FAKE_DEFINITIONS=true
+  stub goenv-hooks "install : true"
+  stub goenv-rehash "true"
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv" "#!/bin/sh"
+
+  run goenv-install 1.2.2
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  assert_line 0 "goenv: warning: multiple goenv installations found in PATH: $(command -v goenv) ${GOENV_TEST_DIR}/bin/goenv, run 'goenv doctor'"
+}
+
+@test "refuses to install as root into a 'GOENV_ROOT' owned by another user" {
+  if [ "$(id -u)" != "0" ]; then
+    skip "needs root"
+  fi
+
+  export USE_FAKE_DEFINITIONS=true
+  mkdir -p "${GOENV_ROOT}"
+  chown nobody "${GOENV_ROOT}"
+
+  run goenv-install 1.2.2
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_failure
+  assert_line 0 "goenv: refusing to install as root into '${GOENV_ROOT}', which is owned by nobody"
+  assert_line 1 "goenv: run 'goenv install' as nobody without sudo, or 'goenv doctor --fix' to repair ownership"
+  assert [ ! -d "${GOENV_ROOT}/versions/1.2.2" ]
+}
+
+@test "installs the first installable version of a fallback list in '.go-version'" {
+  export USE_FAKE_DEFINITIONS=true
+  mkdir -p "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+  echo "1.3rc1 || 1.2.x" > .go-version
+
+  run goenv-install --dry-run
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  assert_line 0 "goenv: 1.3rc1 is not available, trying the next version"
+  assert_line 1 "Using latest patch version 1.2.2"
+  assert_line 5 "  prefix:  ${GOENV_ROOT}/versions/1.2.2"
+}
+
+@test "does not install anything when a version of a fallback list in '.go-version' is installed" {
+  export USE_FAKE_DEFINITIONS=true
+  mkdir -p "${GOENV_ROOT}/versions/1.0.0"
+  mkdir -p "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+  echo "1.3rc1 || 1.0.0 || 1.2.x" > .go-version
+
+  run goenv-install
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  assert_line 0 "goenv: 1.3rc1 is not available, trying the next version"
+  assert_line 1 "goenv: 1.0.0 is already installed"
+  assert [ ! -d "${GOENV_ROOT}/versions/1.2.2" ]
+}
+
+@test "fails when no version of a fallback list in '.go-version' can be installed" {
+  export USE_FAKE_DEFINITIONS=true
+  mkdir -p "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+  echo "1.3rc1 || 1.9.x" > .go-version
+
+  run goenv-install
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_failure
+  assert_line "goenv: none of the versions '1.3rc1 || 1.9.x' can be installed"
+}
+
+@test "prints install events as JSON lines on stdout when '--events' is given" {
+  export USE_FAKE_DEFINITIONS=true
+  stub goenv-hooks "install : true"
+  stub goenv-rehash "true"
+
+  run bash -c "goenv-install --events 1.2.2 2>/dev/null"
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  assert_line 0 "{\"event\":\"resolve\",\"definition\":\"1.2.2\",\"prefix\":\"${GOENV_ROOT}/versions/1.2.2\"}"
+  assert_line 1 "{\"event\":\"download-start\",\"url\":\"http://localhost:8090/1.2.2/1.2.2.tar.gz\"}"
+  assert_equal "resolve download-start download-progress verify extract link rehash done" \
+    "$(echo "$output" | sed 's/^{"event":"\([a-z-]*\)".*/\1/' | uniq | xargs)"
+  assert_line "{\"event\":\"done\",\"version\":\"1.2.2\",\"prefix\":\"${GOENV_ROOT}/versions/1.2.2\",\"status\":0}"
+}
+
+@test "reports the clock skew when the certificate of the server can't be verified" {
+  export USE_FAKE_DEFINITIONS=true
+  stub goenv-hooks "install : true"
+  mkdir -p "${TMP}/bin"
+  cat > "${TMP}/bin/curl" <<SH
+#!/usr/bin/env bash
+if [[ "\$*" == *-qskI* ]]; then
+  printf 'HTTP/1.1 200 OK\r\nDate: %s\r\n\r\n' "$(date -u -d @$(($(date -u +%s) - 3 * 86400)) "+%a, %d %b %Y %T GMT" 2>/dev/null || date -u -v-3d "+%a, %d %b %Y %T GMT")"
+else
+  echo "curl: (60) SSL certificate problem: certificate is not yet valid" >&2
+  exit 60
+fi
+SH
+  chmod +x "${TMP}/bin/curl"
+
+  run goenv-install 1.2.2
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_failure
+  assert_line "go-build: the certificate of http://localhost:8090/1.2.2/1.2.2.tar.gz could not be verified because the system clock is 3 day(s) ahead of the server's"
+  assert_line "go-build: correct the system clock, e.g. with 'sudo timedatectl set-ntp true', and try again"
+}
+
+@test "installs several versions at a time" {
+  export USE_FAKE_DEFINITIONS=true
+  run goenv-install --jobs 2 1.2.0 1.2.2
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  assert [ -f "${GOENV_ROOT}/versions/1.2.0/bin/go" ]
+  assert [ -f "${GOENV_ROOT}/versions/1.2.2/bin/go" ]
+  [[ "$output" == *"[1.2.0] "* ]]
+  [[ "$output" == *"[1.2.2] "* ]]
+}
+
+@test "reports the versions that failed when installing several versions" {
+  export USE_FAKE_DEFINITIONS=true
+  run goenv-install 1.2.0 1.2.3
+  unset USE_FAKE_DEFINITIONS
+
+  assert_failure
+  assert [ -f "${GOENV_ROOT}/versions/1.2.0/bin/go" ]
+  assert_line "goenv: failed to install 1.2.3"
+}
+
+@test "fails when '--jobs' is not a positive number" {
+  run goenv-install --jobs 0 1.2.0 1.2.2
+  assert_failure
+  assert_line 0 "Usage: goenv install [-f] [-kvpq] <version>|latest|unstable"
+}
+
+@test "runs executable install hooks with the installed version" {
+  create_executable "${GOENV_ROOT}/hooks/install" "notify" <<SH
+#!$BASH
+echo "hook \$GOENV_VERSION \$GOENV_PREFIX \$GOENV_HOOK_STATUS"
+SH
+  export USE_FAKE_DEFINITIONS=true
+  run goenv-install 1.2.2
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  assert_line "hook 1.2.2 ${GOENV_ROOT}/versions/1.2.2 0"
+}
+
+@test "installs from the archives of a mirror directory with '--mirror'" {
+  mkdir -p "${GOENV_TEST_DIR}/mirror"
+  cp "${BATS_TEST_DIRNAME}/http-definitions/1.2.2/1.2.2.tar.gz" "${GOENV_TEST_DIR}/mirror"
+  (cd "${GOENV_TEST_DIR}/mirror" && sha256sum 1.2.2.tar.gz > SHA256SUMS)
+
+  export USE_FAKE_DEFINITIONS=true
+  run goenv-install --mirror "${GOENV_TEST_DIR}/mirror" 1.2.2
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  assert_line "-> ${GOENV_TEST_DIR}/mirror/1.2.2.tar.gz"
+  assert [ -f "${GOENV_ROOT}/versions/1.2.2/bin/go" ]
+}
+
+@test "fails without falling back to go.dev when an archive isn't in the SHA256SUMS of 'GOENV_MIRROR'" {
+  mkdir -p "${GOENV_TEST_DIR}/mirror"
+  cp "${BATS_TEST_DIRNAME}/http-definitions/1.2.2/1.2.2.tar.gz" "${GOENV_TEST_DIR}/mirror"
+  touch "${GOENV_TEST_DIR}/mirror/SHA256SUMS"
+
+  export USE_FAKE_DEFINITIONS=true
+  GOENV_MIRROR="${GOENV_TEST_DIR}/mirror" run goenv-install 1.2.2
+  unset USE_FAKE_DEFINITIONS
+
+  assert_failure
+  assert_line "error: 1.2.2.tar.gz is not listed in the SHA256SUMS of ${GOENV_TEST_DIR}/mirror"
+  refute_line "-> http://localhost:8090/1.2.2/1.2.2.tar.gz"
+  assert [ ! -d "${GOENV_ROOT}/versions/1.2.2" ]
+}
+
+@test "fails to install an archive without a SHA-256 checksum with '--require-verification'" {
+  cat > "${GOENV_TEST_DIR}/1.2.2-unverified" <<DEF
+install_linux_64bit "Go Linux 64bit 1.2.2" "http://localhost:8090/1.2.2/1.2.2.tar.gz"
+install_linux_arm_64bit "Go Linux arm 64bit 1.2.2" "http://localhost:8090/1.2.2/1.2.2.tar.gz"
+install_darwin_64bit "Go Darwin 64bit 1.2.2" "http://localhost:8090/1.2.2/1.2.2.tar.gz"
+install_darwin_arm "Go Darwin arm 1.2.2" "http://localhost:8090/1.2.2/1.2.2.tar.gz"
+DEF
+
+  run goenv-install --require-verification "${GOENV_TEST_DIR}/1.2.2-unverified"
+  assert_failure
+  assert [ ! -d "${GOENV_ROOT}/versions/1.2.2-unverified" ]
+
+  export USE_FAKE_DEFINITIONS=true
+  GOENV_REQUIRE_VERIFICATION=1 run goenv-install 1.2.2
+  unset USE_FAKE_DEFINITIONS
+  assert_success
+  assert [ -f "${GOENV_ROOT}/versions/1.2.2/bin/go" ]
+}
+
+@test "verifies the signature of archives with 'GOENV_SIGNING_KEYRING'" {
+  export GNUPGHOME="${GOENV_TEST_DIR}/gnupg"
+  mkdir -p -m 700 "$GNUPGHOME" "${GOENV_TEST_DIR}/mirror"
+  gpg --batch --passphrase '' --quick-gen-key "goenv test <test@example.com>" ed25519 sign 2>/dev/null
+  gpg --export > "${GOENV_TEST_DIR}/keyring.gpg"
+  cp "${BATS_TEST_DIRNAME}/http-definitions/1.2.2/1.2.2.tar.gz" "${GOENV_TEST_DIR}/mirror"
+  echo "tampered" | gpg --batch --armor --detach-sign > "${GOENV_TEST_DIR}/mirror/1.2.2.tar.gz.asc"
+
+  export USE_FAKE_DEFINITIONS=true
+  GOENV_SIGNING_KEYRING="${GOENV_TEST_DIR}/keyring.gpg" run goenv-install --mirror "${GOENV_TEST_DIR}/mirror" 1.2.2
+  assert_failure
+  assert_line "error: the signature of 1.2.2.tar.gz can't be verified with ${GOENV_TEST_DIR}/keyring.gpg"
+  assert [ ! -d "${GOENV_ROOT}/versions/1.2.2" ]
+
+  gpg --batch --armor --detach-sign -o - "${GOENV_TEST_DIR}/mirror/1.2.2.tar.gz" > "${GOENV_TEST_DIR}/mirror/1.2.2.tar.gz.asc"
+  GOENV_SIGNING_KEYRING="${GOENV_TEST_DIR}/keyring.gpg" run goenv-install --mirror "${GOENV_TEST_DIR}/mirror" 1.2.2
+  unset USE_FAKE_DEFINITIONS
+  assert_success
+  assert [ -f "${GOENV_ROOT}/versions/1.2.2/bin/go" ]
+}
+
+@test "resolves 'x' selectors and 'latest:stable' to the latest version it can install" {
+  export USE_FAKE_DEFINITIONS=true
+  run goenv-install --dry-run 1.2.x
+  assert_success
+  assert_line "Using latest patch version 1.2.2"
+
+  run goenv-install --dry-run 1.x
+  assert_success
+  assert_line "Installing latest 1.x version 1.2.2..."
+
+  run goenv-install --dry-run latest:stable
+  assert_success
+  assert_line "Installing latest version 1.2.2..."
+  unset USE_FAKE_DEFINITIONS
+}
+
+@test "resumes a download that was interrupted from its partial file in the cache" {
+  mkdir -p "${GOENV_ROOT}/cache"
+  arch=" "
+  if [ "$(uname -m)" = "aarch64" ] || [ "$(uname -m)" = "arm64" ]; then
+    arch=" arm "
+  fi
+  case "$(uname -s)" in
+  Darwin*) package_filename="Go Darwin 10.8${arch}1.2.2.tar.gz" ;;
+  *) package_filename="Go Linux${arch}64bit 1.2.2.tar.gz" ;;
+  esac
+  head -c 100 "${BATS_TEST_DIRNAME}/http-definitions/1.2.2/1.2.2.tar.gz" > "${GOENV_ROOT}/cache/${package_filename}.partial"
+
+  export USE_FAKE_DEFINITIONS=true
+  run goenv-install -q 1.2.2
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  assert_line "Resuming the download at 100 B..."
+  [[ "$output" == *"Downloaded 1.2.2.tar.gz (190 B in "*"s, resumed at 100 B)"* ]]
+  assert [ ! -e "${GOENV_ROOT}/cache/${package_filename}.partial" ]
+  cmp "${GOENV_ROOT}/cache/${package_filename}" "${BATS_TEST_DIRNAME}/http-definitions/1.2.2/1.2.2.tar.gz"
+  assert [ -f "${GOENV_ROOT}/versions/1.2.2/bin/go" ]
+}
+
+@test "downloads again from zero after a corrupt partial file" {
+  mkdir -p "${GOENV_ROOT}/cache"
+  arch=" "
+  if [ "$(uname -m)" = "aarch64" ] || [ "$(uname -m)" = "arm64" ]; then
+    arch=" arm "
+  fi
+  case "$(uname -s)" in
+  Darwin*) package_filename="Go Darwin 10.8${arch}1.2.2.tar.gz" ;;
+  *) package_filename="Go Linux${arch}64bit 1.2.2.tar.gz" ;;
+  esac
+  printf '%0200d' 0 > "${GOENV_ROOT}/cache/${package_filename}.partial"
+
+  export USE_FAKE_DEFINITIONS=true
+  run goenv-install -q 1.2.2
+  assert_failure
+  assert [ ! -e "${GOENV_ROOT}/cache/${package_filename}.partial" ]
+
+  run goenv-install -q 1.2.2
+  unset USE_FAKE_DEFINITIONS
+  assert_success
+  assert [ -f "${GOENV_ROOT}/versions/1.2.2/bin/go" ]
+}
+
+@test "installs the newest version of a range it can install" {
+  export USE_FAKE_DEFINITIONS=true
+  run goenv-install --dry-run ">=1.1 <1.3"
+  unset USE_FAKE_DEFINITIONS
+  assert_success
+  assert_line "Installing newest version 1.2.2 of '>=1.1 <1.3'..."
+}
+
+@test "refuses to install an official release on a musl system without '--static-ok'" {
+  [ "$(uname -s)" = "Linux" ] || skip "needs Linux"
+  export USE_FAKE_DEFINITIONS=true
+  create_executable "${GOENV_TEST_DIR}/bin" "ldd" <<SH
+#!/bin/sh
+echo "musl libc (x86_64)" >&2
+echo "Version 1.2.4" >&2
+exit 1
+SH
+
+  run goenv-install 1.2.2
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_failure
+  assert_output <<OUT
+goenv: this system uses musl 1.2.4, but official Go releases are built for glibc and may not run without the 'gcompat' package
+goenv: build 1.2.2 for this system with --build-from-source, or use --static-ok to install the release anyway, e.g. for CGO_ENABLED=0 builds
+OUT
+  assert [ ! -d "${GOENV_ROOT}/versions/1.2.2" ]
+}
+
+@test "records the C library of a version installed on a musl system with '--static-ok'" {
+  [ "$(uname -s)" = "Linux" ] || skip "needs Linux"
+  export USE_FAKE_DEFINITIONS=true
+  stub goenv-hooks "install : true"
+  stub goenv-rehash "true"
+  create_executable "${GOENV_TEST_DIR}/bin" "ldd" <<SH
+#!/bin/sh
+echo "musl libc (x86_64)" >&2
+echo "Version 1.2.4" >&2
+exit 1
+SH
+
+  run goenv-install -q --static-ok 1.2.2
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  run grep "^libc=" "${GOENV_ROOT}/versions/1.2.2/.goenv-metadata"
+  assert_success "libc=musl 1.2.4"
+}
+
+@test "records the C library of a version installed on a glibc system" {
+  [ "$(uname -s)" = "Linux" ] || skip "needs Linux"
+  export USE_FAKE_DEFINITIONS=true
+  stub goenv-hooks "install : true"
+  stub goenv-rehash "true"
+  create_executable "${GOENV_TEST_DIR}/bin" "ldd" <<SH
+#!/bin/sh
+echo "ldd (GNU libc) 2.39"
+SH
+
+  run goenv-install -q 1.2.2
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  run grep "^libc=" "${GOENV_ROOT}/versions/1.2.2/.goenv-metadata"
+  assert_success "libc=glibc 2.39"
+}
+
+@test "records the archive a version was installed from for 'goenv verify'" {
+  export USE_FAKE_DEFINITIONS=true
+  stub goenv-hooks "install : true"
+  stub goenv-rehash "true"
+
+  run goenv-install -q 1.2.2
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  run grep "^archive_" "${GOENV_ROOT}/versions/1.2.2/.goenv-metadata"
+  assert_success
+  assert_line 0 "archive_url=http://localhost:8090/1.2.2/1.2.2.tar.gz"
+  assert_line 1 "archive_sha256=d7518d03fc9d7ac1d32c49358594ff6517712c3d3de4f80ebaa3229361f38937"
+}
+
+@test "builds a version from source with '--build', bootstrapped by the newest installed version" {
+  export USE_FAKE_DEFINITIONS=true
+  export GOENV_RELEASES_URL="http://localhost:8090/releases.json"
+  stub goenv-hooks "install : true"
+  stub goenv-rehash "true"
+  create_executable "1.0.0" "go" <<SH
+#!/bin/sh
+SH
+  create_executable "1.2.0" "go" <<SH
+#!/bin/sh
+SH
+
+  run goenv-install -q --build 1.2.2
+
+  unset USE_FAKE_DEFINITIONS GOENV_RELEASES_URL
+
+  assert_success
+  assert_line "-> http://localhost:8090/1.2.2/go1.2.2.src.tar.gz"
+  assert_line "Installing Go 1.2.2 from source..."
+  assert_line "Building with make.bash, bootstrapped by ${GOENV_ROOT}/versions/1.2.0..."
+  run "${GOENV_ROOT}/versions/1.2.2/bin/go"
+  assert_success "go version go1.2.2 built with ${GOENV_ROOT}/versions/1.2.0"
+}
+
+# Creates a git repository of Go at GO_BUILD_TIP_URL, whose make.bash
+# builds a go that prints the commit message.
+commit_tip() {
+  local repo="${GOENV_TEST_DIR}/go.git"
+  export GO_BUILD_TIP_URL="file://${repo}"
+  mkdir -p "${repo}/src"
+  cat > "${repo}/src/make.bash" <<SH
+#!/bin/sh
+mkdir -p ../bin
+printf '#!/bin/sh\necho "go version devel ${1}"\n' > ../bin/go
+chmod +x ../bin/go
+SH
+  chmod +x "${repo}/src/make.bash"
+  git -C "$repo" init -q -b master
+  git -C "$repo" add -A
+  git -C "$repo" -c user.name=goenv -c user.email=goenv@example.com commit -q -m "$1"
+}
+
+@test "builds the development branch as 'tip' with 'gotip'" {
+  stub goenv-hooks "install : true"
+  stub goenv-rehash "true"
+  commit_tip "first"
+
+  GOROOT_BOOTSTRAP=/opt/go run goenv-install -q gotip
+
+  assert_success
+  assert_line "Cloning ${GO_BUILD_TIP_URL}..."
+  assert_line "Building with make.bash, bootstrapped by /opt/go..."
+  run "${GOENV_ROOT}/versions/tip/bin/go"
+  assert_success "go version devel first"
+}
+
+@test "updates tip with 'gotip --update'" {
+  stub goenv-hooks "install : true" "install : true"
+  stub goenv-rehash "true" "true"
+  commit_tip "first"
+  GOROOT_BOOTSTRAP=/opt/go goenv-install -q gotip
+  touch "${GOENV_ROOT}/versions/tip/removed"
+  commit_tip "second"
+
+  GOROOT_BOOTSTRAP=/opt/go run goenv-install -q gotip
+  assert_success "goenv: tip is already installed, update it with 'goenv install gotip --update'"
+
+  GOROOT_BOOTSTRAP=/opt/go run goenv-install -q gotip --update
+  assert_success
+  run "${GOENV_ROOT}/versions/tip/bin/go"
+  assert_success "go version devel second"
+  assert [ ! -e "${GOENV_ROOT}/versions/tip/removed" ]
+}
+
+@test "fails to update a version other than tip" {
+  run goenv-install --update 1.2.2
+  assert_failure "goenv: --update only updates gotip, reinstall 1.2.2 with --force"
+}
+
+@test "builds a version from source bootstrapped by 'GOROOT_BOOTSTRAP'" {
+  export USE_FAKE_DEFINITIONS=true
+  export GOENV_RELEASES_URL="http://localhost:8090/releases.json"
+  stub goenv-hooks "install : true"
+  stub goenv-rehash "true"
+
+  GOROOT_BOOTSTRAP=/opt/go run goenv-install -q --build-from-source 1.2.2
+
+  unset USE_FAKE_DEFINITIONS GOENV_RELEASES_URL
+
+  assert_success
+  assert_line "Building with make.bash, bootstrapped by /opt/go..."
+  run "${GOENV_ROOT}/versions/1.2.2/bin/go"
+  assert_success "go version go1.2.2 built with /opt/go"
+}
+
+@test "downloads the latest release to bootstrap a build from source when no version is installed" {
+  export USE_FAKE_DEFINITIONS=true
+  export GOENV_RELEASES_URL="http://localhost:8090/releases.json"
+  stub goenv-hooks "install : true"
+  stub goenv-rehash "true"
+
+  run goenv-install -q --build 1.2.2
+
+  unset USE_FAKE_DEFINITIONS GOENV_RELEASES_URL
+
+  assert_success
+  assert_line "Downloading Go 1.2.2 to bootstrap the build..."
+  run "${GOENV_ROOT}/versions/1.2.2/bin/go"
+  assert_success
+  [[ "$output" == "go version go1.2.2 built with "*"/go-build."*"/bootstrap" ]]
+}
+
+@test "applies a patch from stdin before building from source with '--patch'" {
+  export USE_FAKE_DEFINITIONS=true
+  export GOENV_RELEASES_URL="http://localhost:8090/releases.json"
+  stub goenv-hooks "install : true"
+  stub goenv-rehash "true"
+
+  GOROOT_BOOTSTRAP=/opt/go run goenv-install -q --build --patch 1.2.2 <<'PATCH'
+--- a/src/make.bash
++++ b/src/make.bash
+@@ -1,4 +1,4 @@
+ #!/bin/sh
+ mkdir -p ../bin
+-printf '#!/bin/sh\necho "go version go1.2.2 built with %s"\n' "$GOROOT_BOOTSTRAP" > ../bin/go
++printf '#!/bin/sh\necho "go version go1.2.2 patched, built with %s"\n' "$GOROOT_BOOTSTRAP" > ../bin/go
+ chmod +x ../bin/go
+PATCH
+
+  unset USE_FAKE_DEFINITIONS GOENV_RELEASES_URL
+
+  assert_success
+  assert_line "Applying the patch from stdin..."
+  run "${GOENV_ROOT}/versions/1.2.2/bin/go"
+  assert_success "go version go1.2.2 patched, built with /opt/go"
+}
+
+@test "fails to apply a patch to a release installed without '--build'" {
+  export USE_FAKE_DEFINITIONS=true
+
+  run goenv-install -q --patch 1.2.2 </dev/null
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_failure "go-build: --patch needs --build, releases are installed as they are"
+  assert [ ! -d "${GOENV_ROOT}/versions/1.2.2" ]
+}
+
+@test "prints the plan of a build from source with '--build --dry-run'" {
+  export USE_FAKE_DEFINITIONS=true
+  export GOENV_RELEASES_URL="http://localhost:8090/releases.json"
+
+  run goenv-install --build --dry-run 1.2.2
+
+  unset USE_FAKE_DEFINITIONS GOENV_RELEASES_URL
+
+  assert_success_out <<OUT
+Would install Go 1.2.2 from source
+  url:     http://localhost:8090/1.2.2/go1.2.2.src.tar.gz
+  sha256:  51196f248f787df05b28b86d818e207422a13fd1f6007937e9dfd1002a63bc60
+  prefix:  ${GOENV_ROOT}/versions/1.2.2
+  disk:    unknown, no installed version to estimate it from
+  tools:   none
+OUT
+}
@@ -0,0 +1,93 @@
+#!/usr/bin/env bats
+
+project_root="$(git rev-parse --show-toplevel)"
+load test_helper
+
+export PATH="${project_root}/libexec:$PATH"
+
+@test "has usage instructions" {
+  run goenv-help --usage queue
+  assert_success_out <<OUT
+Usage: goenv queue [list]
+       goenv queue run
+       goenv queue remove <version>
+       goenv queue clear
+OUT
+}
+
+@test "has completion support" {
+  run goenv-queue --complete
+  assert_success_out <<OUT
+list
+run
+remove
+clear
+OUT
+}
+
+@test "prints nothing when no versions are queued" {
+  run goenv-queue
+  assert_success ""
+}
+
+@test "lists queued versions" {
+  mkdir -p "$GOENV_ROOT"
+  printf '1.2.0\n1.2.2\n' > "${GOENV_ROOT}/queue"
+
+  run goenv-queue list
+
+  assert_success_out <<OUT
+1.2.0
+1.2.2
+OUT
+}
+
+@test "removes a queued version" {
+  mkdir -p "$GOENV_ROOT"
+  printf '1.2.0\n1.2.2\n' > "${GOENV_ROOT}/queue"
+
+  run goenv-queue remove 1.2.0
+
+  assert_success ""
+  assert_equal "1.2.2" "$(cat "${GOENV_ROOT}/queue")"
+}
+
+@test "fails to remove a version that is not queued" {
+  run goenv-queue remove 1.2.0
+  assert_failure "goenv: version '1.2.0' is not queued"
+}
+
+@test "clears the queue" {
+  mkdir -p "$GOENV_ROOT"
+  echo 1.2.0 > "${GOENV_ROOT}/queue"
+
+  run goenv-queue clear
+
+  assert_success ""
+  assert [ ! -e "${GOENV_ROOT}/queue" ]
+}
+
+@test "installs queued versions and removes them from the queue when 'run' is given" {
+  export USE_FAKE_DEFINITIONS=true
+  mkdir -p "$GOENV_ROOT"
+  echo 1.2.2 > "${GOENV_ROOT}/queue"
+
+  run goenv-queue run
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  assert [ -f "${GOENV_ROOT}/versions/1.2.2/bin/go" ]
+  assert [ ! -e "${GOENV_ROOT}/queue" ]
+}
+
+@test "keeps versions that fail to install in the queue when 'run' is given" {
+  mkdir -p "$GOENV_ROOT"
+  echo 1.2.3 > "${GOENV_ROOT}/queue"
+
+  run goenv-queue run
+
+  assert_failure
+  assert_line "goenv: failed to install queued version 1.2.3, it stays queued"
+  assert_equal "1.2.3" "$(cat "${GOENV_ROOT}/queue")"
+}
@@ -0,0 +1,120 @@
+#!/usr/bin/env bats
+
+project_root="$(git rev-parse --show-toplevel)"
+load test_helper
+
+export PATH="${project_root}/libexec:$PATH"
+
+# Stubs curl with a server that answers with the given statuses in turn,
+# the last one repeating, and honors conditional requests.
+stub_server() {
+  mkdir -p "${GOENV_TEST_DIR}/bin"
+  printf '%s\n' "$@" > "${GOENV_TEST_DIR}/statuses"
+  cat > "${GOENV_TEST_DIR}/bin/curl" <<SH
+#!/usr/bin/env bash
+while [ "\$#" -gt 1 ]; do
+  case "\$1" in
+  -H ) headers+=("\$2"); shift ;;
+  -D ) headers_file="\$2"; shift ;;
+  -o ) output_file="\$2"; shift ;;
+  esac
+  shift
+done
+status="\$(head -n 1 "${GOENV_TEST_DIR}/statuses")"
+[ "\$(wc -l < "${GOENV_TEST_DIR}/statuses")" -eq 1 ] || sed -i.bak 1d "${GOENV_TEST_DIR}/statuses"
+echo "\${headers[*]}" >> "${GOENV_TEST_DIR}/requests"
+[ "\$status" != "fail" ] || exit 6
+if [ "\$status" = "200" ] && [[ "\${headers[*]}" == *'If-None-Match: "v1"'* ]]; then
+  status=304
+fi
+printf 'HTTP/1.1 %s\r\nETag: "v1"\r\n\r\n' "\$status" > "\$headers_file"
+[ "\$status" != "200" ] || cat "${GOENV_TEST_DIR}/releases.json" > "\$output_file"
+echo -n "\$status"
+SH
+  chmod +x "${GOENV_TEST_DIR}/bin/curl"
+}
+
+releases() {
+  local version
+  echo "["
+  for version; do
+    cat <<JSON
+ {
+  "version": "go${version}",
+  "stable": true,
+  "files": [
+   {"filename": "go${version}.src.tar.gz", "os": "", "arch": "", "version": "go${version}", "sha256": "$(printf '%064d' 1)", "size": 1, "kind": "source"},
+   {"filename": "go${version}.linux-amd64.tar.gz", "os": "linux", "arch": "amd64", "version": "go${version}", "sha256": "$(printf '%064d' 2)", "size": 1, "kind": "archive"},
+   {"filename": "go${version}.darwin-arm64.tar.gz", "os": "darwin", "arch": "arm64", "version": "go${version}", "sha256": "$(printf '%064d' 3)", "size": 1, "kind": "archive"}
+  ]
+ },
+JSON
+  done
+  echo "]"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage refresh
+  assert_success_out <<OUT
+Usage: goenv refresh [--force]
+       goenv refresh --history
+OUT
+}
+
+@test "adds definitions for releases that go-build doesn't ship" {
+  releases 1.99.0 1.22.4 > "${GOENV_TEST_DIR}/releases.json"
+  stub_server 200
+
+  run goenv-refresh
+  assert_success "goenv: refreshed the release list, 1 new and 0 changed definitions"
+  [ ! -f "${GOENV_ROOT}/definitions/1.22.4" ]
+  [ -f "${GOENV_ROOT}/.cache/refresh/releases.json" ]
+  [ ! -d "${GOENV_ROOT}/cache" ]
+
+  run cat "${GOENV_ROOT}/definitions/1.99.0"
+  assert_success_out <<OUT
+install_linux_64bit "Go Linux 64bit 1.99.0" "go1.99.0.linux-amd64.tar.gz#$(printf '%064d' 2)"
+
+install_darwin_arm "Go Darwin arm 1.99.0" "go1.99.0.darwin-arm64.tar.gz#$(printf '%064d' 3)"
+OUT
+}
+
+@test "only fetches the release list again if it changed" {
+  releases 1.99.0 > "${GOENV_TEST_DIR}/releases.json"
+  stub_server 200
+
+  run goenv-refresh
+  assert_success
+  run goenv-refresh
+  assert_success "goenv: the release list didn't change"
+  assert_equal 'If-None-Match: "v1"' "$(tail -n 1 "${GOENV_TEST_DIR}/requests")"
+
+  run goenv-refresh --force
+  assert_success "goenv: refreshed the release list, 0 new and 0 changed definitions"
+}
+
+@test "retries failed requests" {
+  releases 1.99.0 > "${GOENV_TEST_DIR}/releases.json"
+  stub_server fail 503 200
+
+  GOENV_REFRESH_RETRIES=2 run goenv-refresh
+  assert_success "goenv: refreshed the release list, 1 new and 0 changed definitions"
+  assert_equal 3 "$(wc -l < "${GOENV_TEST_DIR}/requests" | tr -d ' ')"
+}
+
+@test "keeps the definitions of the last refresh when the list can't be fetched" {
+  releases 1.99.0 > "${GOENV_TEST_DIR}/releases.json"
+  stub_server 200
+  goenv-refresh
+  stub_server 503
+
+  GOENV_REFRESH_RETRIES=0 run goenv-refresh
+  assert_failure
+  [[ "$output" == "goenv: failed to refresh the release list (503), keeping the list from 20"* ]]
+  [ -f "${GOENV_ROOT}/definitions/1.99.0" ]
+
+  run goenv-refresh --history
+  assert_success
+  [[ "${lines[0]}" =~ ^[0-9T:-]+Z\ ok\ status=200\ attempts=1\ added=1\ changed=0$ ]]
+  [[ "${lines[1]}" =~ ^[0-9T:-]+Z\ failed\ status=503\ attempts=1$ ]]
+}
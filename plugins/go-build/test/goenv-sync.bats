@@ -0,0 +1,93 @@
+#!/usr/bin/env bats
+
+project_root="$(git rev-parse --show-toplevel)"
+load test_helper
+
+export PATH="${project_root}/libexec:$PATH"
+
+@test "has usage instructions" {
+  run goenv-help --usage sync
+  assert_success_out <<OUT
+Usage: goenv sync [--prune] [-n|--dry-run] [<file>]
+OUT
+}
+
+@test "has completion support" {
+  run goenv-sync --complete
+  assert_success_out <<OUT
+--prune
+--dry-run
+OUT
+}
+
+@test "fails when no version list is found" {
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+
+  run goenv-sync
+
+  assert_failure "goenv: no .go-versions or goenv.yaml found in '${GOENV_TEST_DIR}/project' or its parents"
+}
+
+@test "installs the missing versions listed in '.go-versions'" {
+  export USE_FAKE_DEFINITIONS=true
+  mkdir -p "${GOENV_ROOT}/versions/1.2.0/bin" "${GOENV_TEST_DIR}/project/cmd"
+  printf '# Supported versions\n1.2.0\n1.2.2\n' > "${GOENV_TEST_DIR}/project/.go-versions"
+  cd "${GOENV_TEST_DIR}/project/cmd"
+
+  run goenv-sync
+
+  unset USE_FAKE_DEFINITIONS
+
+  assert_success
+  assert [ -f "${GOENV_ROOT}/versions/1.2.2/bin/go" ]
+}
+
+@test "reads the versions from 'goenv.yaml'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.2.0/bin" "${GOENV_TEST_DIR}/project"
+  cat > "${GOENV_TEST_DIR}/project/goenv.yaml" <<YAML
+tools:
+  - gopls
+versions:
+  - 1.2.0
+  - "1.2.2"  # the next release
+YAML
+  cd "${GOENV_TEST_DIR}/project"
+
+  run goenv-sync --dry-run
+
+  assert_success "goenv: would install 1.2.2"
+}
+
+@test "uninstalls versions that aren't listed with '--prune'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.2.0/bin" "${GOENV_ROOT}/versions/1.3.1/bin" "${GOENV_ROOT}/versions/1.4.0/bin"
+  printf '1.2.0\n1.3\n' > "${GOENV_TEST_DIR}/versions.txt"
+
+  run goenv-sync --prune "${GOENV_TEST_DIR}/versions.txt"
+
+  assert_success
+  assert [ -d "${GOENV_ROOT}/versions/1.2.0" ]
+  assert [ -d "${GOENV_ROOT}/versions/1.3.1" ]
+  assert [ ! -d "${GOENV_ROOT}/versions/1.4.0" ]
+}
+
+@test "doesn't prune when a listed version fails to install" {
+  mkdir -p "${GOENV_ROOT}/versions/1.4.0/bin"
+  echo 1.2.3 > "${GOENV_TEST_DIR}/versions.txt"
+
+  run goenv-sync --prune "${GOENV_TEST_DIR}/versions.txt"
+
+  assert_failure
+  assert_line "goenv: failed to install 1.2.3"
+  assert_line "goenv: skipped pruning since not all versions were installed"
+  assert [ -d "${GOENV_ROOT}/versions/1.4.0" ]
+}
+
+@test "installs from a file with 'goenv install --from-file'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.2.0/bin" "${GOENV_ROOT}/versions/1.4.0/bin"
+  echo 1.2.0 > "${GOENV_TEST_DIR}/versions.txt"
+
+  run goenv-install --from-file --prune --dry-run "${GOENV_TEST_DIR}/versions.txt"
+
+  assert_success "goenv: would uninstall 1.4.0"
+}
@@ -9,6 +9,8 @@ export PATH="${project_root}/libexec:$PATH"
   run goenv-help --usage uninstall
   assert_success_out <<OUT
 Usage: goenv uninstall [-f|--force] <version>
+       goenv uninstall [-f|--force] -i|--interactive
+       goenv uninstall --prune [-n|--dry-run]
 OUT
 }
 
@@ -16,6 +18,9 @@ OUT
   run goenv-uninstall --complete
   assert_success_out <<OUT
 --force
+--interactive
+--prune
+--dry-run
 OUT
 }
 
@@ -23,9 +28,18 @@ OUT
   run goenv-uninstall -h
   assert_success_out <<'OUT'
 Usage: goenv uninstall [-f|--force] <version>
+       goenv uninstall [-f|--force] -i|--interactive
+       goenv uninstall --prune [-n|--dry-run]
 
    -f  Attempt to remove the specified version without prompting
        for confirmation. Still displays error message if version does not exist.
+   -i  Pick the version to uninstall from the installed ones, see
+       `goenv help prompt'
+
+   --prune       Remove every patch release of a minor version but the
+                 latest, keeping the versions pinned by a version file
+                 (see `goenv help prune')
+   -n/--dry-run  Print what --prune would remove without removing anything
 
 See `goenv versions` for a complete list of installed versions.
 OUT
@@ -35,9 +49,18 @@ OUT
   run goenv-uninstall --help
   assert_success_out <<'OUT'
 Usage: goenv uninstall [-f|--force] <version>
+       goenv uninstall [-f|--force] -i|--interactive
+       goenv uninstall --prune [-n|--dry-run]
 
    -f  Attempt to remove the specified version without prompting
        for confirmation. Still displays error message if version does not exist.
+   -i  Pick the version to uninstall from the installed ones, see
+       `goenv help prompt'
+
+   --prune       Remove every patch release of a minor version but the
+                 latest, keeping the versions pinned by a version file
+                 (see `goenv help prune')
+   -n/--dry-run  Print what --prune would remove without removing anything
 
 See `goenv versions` for a complete list of installed versions.
 OUT
@@ -47,9 +70,18 @@ OUT
   run goenv-uninstall
   assert_failure_out <<'OUT'
 Usage: goenv uninstall [-f|--force] <version>
+       goenv uninstall [-f|--force] -i|--interactive
+       goenv uninstall --prune [-n|--dry-run]
 
    -f  Attempt to remove the specified version without prompting
        for confirmation. Still displays error message if version does not exist.
+   -i  Pick the version to uninstall from the installed ones, see
+       `goenv help prompt'
+
+   --prune       Remove every patch release of a minor version but the
+                 latest, keeping the versions pinned by a version file
+                 (see `goenv help prune')
+   -n/--dry-run  Print what --prune would remove without removing anything
 
 See `goenv versions` for a complete list of installed versions.
 OUT
@@ -59,9 +91,18 @@ OUT
   run goenv-uninstall -f
   assert_failure_out <<'OUT'
 Usage: goenv uninstall [-f|--force] <version>
+       goenv uninstall [-f|--force] -i|--interactive
+       goenv uninstall --prune [-n|--dry-run]
 
    -f  Attempt to remove the specified version without prompting
        for confirmation. Still displays error message if version does not exist.
+   -i  Pick the version to uninstall from the installed ones, see
+       `goenv help prompt'
+
+   --prune       Remove every patch release of a minor version but the
+                 latest, keeping the versions pinned by a version file
+                 (see `goenv help prune')
+   -n/--dry-run  Print what --prune would remove without removing anything
 
 See `goenv versions` for a complete list of installed versions.
 OUT
@@ -71,9 +112,18 @@ OUT
   run goenv-uninstall --force
   assert_failure_out <<'OUT'
 Usage: goenv uninstall [-f|--force] <version>
+       goenv uninstall [-f|--force] -i|--interactive
+       goenv uninstall --prune [-n|--dry-run]
 
    -f  Attempt to remove the specified version without prompting
        for confirmation. Still displays error message if version does not exist.
+   -i  Pick the version to uninstall from the installed ones, see
+       `goenv help prompt'
+
+   --prune       Remove every patch release of a minor version but the
+                 latest, keeping the versions pinned by a version file
+                 (see `goenv help prune')
+   -n/--dry-run  Print what --prune would remove without removing anything
 
 See `goenv versions` for a complete list of installed versions.
 OUT
@@ -83,9 +133,18 @@ OUT
   run goenv-uninstall -f -
   assert_failure_out <<'OUT'
 Usage: goenv uninstall [-f|--force] <version>
+       goenv uninstall [-f|--force] -i|--interactive
+       goenv uninstall --prune [-n|--dry-run]
 
    -f  Attempt to remove the specified version without prompting
        for confirmation. Still displays error message if version does not exist.
+   -i  Pick the version to uninstall from the installed ones, see
+       `goenv help prompt'
+
+   --prune       Remove every patch release of a minor version but the
+                 latest, keeping the versions pinned by a version file
+                 (see `goenv help prune')
+   -n/--dry-run  Print what --prune would remove without removing anything
 
 See `goenv versions` for a complete list of installed versions.
 OUT
@@ -95,9 +154,18 @@ OUT
   run goenv-uninstall --force
   assert_failure_out <<'OUT'
 Usage: goenv uninstall [-f|--force] <version>
+       goenv uninstall [-f|--force] -i|--interactive
+       goenv uninstall --prune [-n|--dry-run]
 
    -f  Attempt to remove the specified version without prompting
        for confirmation. Still displays error message if version does not exist.
+   -i  Pick the version to uninstall from the installed ones, see
+       `goenv help prompt'
+
+   --prune       Remove every patch release of a minor version but the
+                 latest, keeping the versions pinned by a version file
+                 (see `goenv help prune')
+   -n/--dry-run  Print what --prune would remove without removing anything
 
 See `goenv versions` for a complete list of installed versions.
 OUT
@@ -218,3 +286,62 @@ OUT
   assert [ ! -d "${GOENV_ROOT}/versions/1.2.3" ]
   assert [ ! -e "${GOENV_ROOT}/shims/gofmt" ]
 }
+
+@test "runs executable uninstall hooks with the uninstalled version" {
+  create_executable "1.10.3" "gofmt"
+  create_executable "${GOENV_ROOT}/hooks/uninstall" "notify" <<SH
+#!$BASH
+echo "hook \$GOENV_VERSION \$GOENV_PREFIX"
+SH
+
+  run goenv-uninstall -f 1.10.3
+
+  assert_success "hook 1.10.3 ${GOENV_ROOT}/versions/1.10.3"
+}
+
+@test "removes all but the latest patch release of each minor version with '--prune'" {
+  for version in 1.21.3 1.22.1 1.22.4 1.22.5; do
+    create_executable "$version" go <<SH
+#!/bin/sh
+SH
+  done
+  mkdir -p "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+  echo "1.22.1" > .go-version
+
+  run goenv-uninstall --prune --dry-run
+  assert_success_out <<OUT
+Retention policy: keep pinned versions, and the latest 1 patch release(s) of each minor version
+  keep    1.21.3
+  keep    1.22.1  (pinned by ${GOENV_TEST_DIR}/.go-version)
+  remove  1.22.4  (not among the latest 1 patch release(s) of 1.22)
+  keep    1.22.5
+OUT
+  assert [ -d "${GOENV_ROOT}/versions/1.22.4" ]
+
+  run goenv-uninstall --prune
+  assert_success
+  assert_line "Removed 1 version(s): 1.22.4"
+  assert [ ! -d "${GOENV_ROOT}/versions/1.22.4" ]
+  assert [ -d "${GOENV_ROOT}/versions/1.22.5" ]
+}
+
+@test "fails and prints full usage when '--prune' is given with a version" {
+  run goenv-uninstall --prune 1.22.4
+  assert_failure
+  assert_line "       goenv uninstall --prune [-n|--dry-run]"
+}
+
+@test "uninstalls the version picked with '--interactive'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.21.0" "${GOENV_ROOT}/versions/1.22.0"
+
+  run goenv-uninstall -f --interactive <<< "2"
+  assert_success
+  assert [ -d "${GOENV_ROOT}/versions/1.21.0" ]
+  assert [ ! -d "${GOENV_ROOT}/versions/1.22.0" ]
+}
+
+@test "fails to pick a version to uninstall when none is installed" {
+  run goenv-uninstall -i
+  assert_failure "goenv: no versions installed"
+}
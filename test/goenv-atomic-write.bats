@@ -0,0 +1,82 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage atomic-write
+  assert_success_out <<OUT
+Usage: goenv atomic-write <file>
+       goenv atomic-write --update <file> <command> [arg1 arg2...]
+OUT
+}
+
+@test "fails and prints usage without a file" {
+  run goenv-atomic-write
+  assert_failure
+  assert_line 0 "Usage: goenv atomic-write <file>"
+}
+
+@test "replaces a file with stdin and keeps its mode" {
+  echo "1.21.0" > .go-version
+  chmod 640 .go-version
+
+  run goenv-atomic-write .go-version <<< "1.22.5"
+  assert_success ""
+  assert_equal "1.22.5" "$(cat .go-version)"
+  assert_equal "640" "$(stat -c %a .go-version 2>/dev/null || stat -f %Lp .go-version)"
+  assert_equal ".go-version" "$(ls -A)"
+}
+
+@test "creates a file with the default mode" {
+  umask 022
+  run goenv-atomic-write version <<< "1.22.5"
+  assert_success ""
+  assert_equal "644" "$(stat -c %a version 2>/dev/null || stat -f %Lp version)"
+}
+
+@test "updates a file with the output of a command" {
+  printf "b\na\n" > projects
+
+  run goenv-atomic-write --update projects sort
+  assert_success ""
+  assert_equal "a b" "$(cat projects | xargs)"
+  assert [ ! -e projects.lock ]
+}
+
+@test "updates a file that does not exist" {
+  run goenv-atomic-write --update projects echo "a"
+  assert_success ""
+  assert_equal "a" "$(cat projects)"
+}
+
+@test "leaves a file alone when the update fails" {
+  echo "a" > projects
+
+  run goenv-atomic-write --update projects sh -c 'echo partial; exit 1'
+  assert_failure
+  assert_equal "a" "$(cat projects)"
+  assert_equal "projects" "$(ls -A)"
+}
+
+@test "takes over the lock of a process that died" {
+  echo "a" > projects
+  mkdir projects.lock
+  sh -c 'echo $$ > projects.lock/pid'
+
+  run goenv-atomic-write --update projects sort
+  assert_success ""
+}
+
+@test "times out waiting for a lock that is held" {
+  echo "a" > projects
+  mkdir projects.lock
+  echo "$$" > projects.lock/pid
+
+  GOENV_LOCK_TIMEOUT=0 run goenv-atomic-write --update projects sort
+  assert_failure "goenv: timed out waiting for the lock on 'projects' (remove 'projects.lock' if no goenv is running)"
+}
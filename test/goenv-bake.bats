@@ -0,0 +1,103 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+
+  # Installs a fake Go that reports its version, builds nothing and
+  # installs a tool by touching it in GOBIN.
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv-install" <<SH
+#!/usr/bin/env bash
+version="\${2}"
+mkdir -p "\${GOENV_ROOT}/versions/\${version}/bin" "\${GOENV_ROOT}/versions/\${version}/doc"
+cat > "\${GOENV_ROOT}/versions/\${version}/bin/go" <<GO
+#!/bin/sh
+case "\\\$1" in
+version ) echo "go version go\${version} linux/amd64" ;;
+build ) echo "built \\\$2" ;;
+install ) pkg="\\\${2%@*}"; mkdir -p "\\\$GOBIN" && touch "\\\$GOBIN/\\\${pkg##*/}" && chmod +x "\\\$GOBIN/\\\${pkg##*/}" ;;
+esac
+GO
+chmod +x "\${GOENV_ROOT}/versions/\${version}/bin/go"
+echo "installed \${version}"
+SH
+
+  cat > manifest.txt <<TXT
+# Golden image
+go 1.22.5
+
+tool golang.org/x/tools/cmd/stringer@v0.24.0
+TXT
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage bake
+  assert_success "Usage: goenv bake [--minimal] [--no-prewarm] [--report <file>] <manifest>"
+}
+
+@test "fails and prints usage without a manifest" {
+  run goenv-bake --minimal
+  assert_failure "Usage: goenv bake [--minimal] [--no-prewarm] [--report <file>] <manifest>"
+}
+
+@test "fails on unknown directives in the manifest" {
+  echo "golang 1.22.5" > bad.txt
+
+  run goenv-bake bad.txt
+  assert_failure "goenv: bad.txt:1: unknown directive 'golang', expected 'go' or 'tool'"
+}
+
+@test "fails when the manifest lists no Go versions" {
+  echo "tool golang.org/x/tools/cmd/stringer" > tools.txt
+
+  run goenv-bake tools.txt
+  assert_failure "goenv: tools.txt lists no Go versions"
+}
+
+@test "installs versions and tools, prewarms the cache and reports them" {
+  run goenv-bake --report report.json manifest.txt
+  assert_success_out <<OUT
+Baking go 1.22.5...
+installed 1.22.5
+Installing golang.org/x/tools/cmd/stringer@v0.24.0 for go 1.22.5...
+Prewarming the build cache of go 1.22.5...
+built std
+OUT
+
+  run cat report.json
+  assert_success_out <<OUT
+{
+  "goenv_root": "${GOENV_ROOT}",
+  "versions": [
+    {"version": "1.22.5", "prefix": "${GOENV_ROOT}/versions/1.22.5", "minimal": false, "tools": [{"package": "golang.org/x/tools/cmd/stringer@v0.24.0", "path": "${HOME}/go/1.22.5/bin/stringer"}], "verified": true}
+  ]
+}
+OUT
+  assert [ -d "${GOENV_ROOT}/versions/1.22.5/doc" ]
+  assert [ -x "${GOENV_ROOT}/shims/go" ]
+}
+
+@test "removes documentation with --minimal" {
+  run goenv-bake --minimal --no-prewarm manifest.txt
+  assert_success
+  refute_line "built std"
+  assert_line '    {"version": "1.22.5", "prefix": "'"${GOENV_ROOT}"'/versions/1.22.5", "minimal": true, "tools": [{"package": "golang.org/x/tools/cmd/stringer@v0.24.0", "path": "'"${HOME}"'/go/1.22.5/bin/stringer"}], "verified": true}'
+  assert [ ! -d "${GOENV_ROOT}/versions/1.22.5/doc" ]
+}
+
+@test "fails when a version or tool does not verify" {
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv-install" <<SH
+#!/usr/bin/env bash
+mkdir -p "\${GOENV_ROOT}/versions/\${2}/bin"
+printf '#!/bin/sh\\necho "go version go1.21.0 linux/amd64"\\n' > "\${GOENV_ROOT}/versions/\${2}/bin/go"
+chmod +x "\${GOENV_ROOT}/versions/\${2}/bin/go"
+SH
+
+  run goenv-bake --no-prewarm manifest.txt
+  assert_failure
+  assert_line "goenv: go 1.22.5 failed to verify, 'go version' does not report it"
+  assert_line "goenv: golang.org/x/tools/cmd/stringer@v0.24.0 failed to verify for go 1.22.5, '${HOME}/go/1.22.5/bin/stringer' does not exist"
+  assert_line '    {"version": "1.22.5", "prefix": "'"${GOENV_ROOT}"'/versions/1.22.5", "minimal": false, "tools": [{"package": "golang.org/x/tools/cmd/stringer@v0.24.0", "path": "'"${HOME}"'/go/1.22.5/bin/stringer"}], "verified": false}'
+}
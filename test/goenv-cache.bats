@@ -0,0 +1,187 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+create_cache_entry() {
+  mkdir -p "${1}/${2:0:2}"
+  touch "${1}/${2:0:2}/${2}-a" "${1}/${2:0:2}/${2}-d"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage cache
+  assert_success_out <<OUT
+Usage: goenv cache stats
+       goenv cache size
+       goenv cache clean build|mod|releases [--older-than <days>d]
+       goenv cache path [build|mod|releases]
+OUT
+}
+
+@test "has completion support" {
+  run goenv-cache --complete
+  assert_success_out <<OUT
+stats
+size
+clean
+path
+OUT
+
+  run goenv-cache --complete clean
+  assert_success_out <<OUT
+build
+mod
+releases
+--older-than
+OUT
+}
+
+@test "fails and prints usage when no subcommand is given" {
+  run goenv-cache
+  assert_failure
+  assert_line 0 "Usage: goenv cache stats"
+}
+
+@test "fails when there are no build caches" {
+  GOENV_GOCACHE_DIR="${GOENV_TEST_DIR}/gocache" run goenv-cache stats
+  assert_failure "goenv: no Go build caches found"
+}
+
+@test "reports each per-version build cache in 'GOENV_GOCACHE_DIR'" {
+  export GOENV_GOCACHE_DIR="${GOENV_TEST_DIR}/gocache"
+  cache="${GOENV_GOCACHE_DIR}/1.22.5-linux-amd64"
+  create_cache_entry "$cache" "aa01"
+  create_cache_entry "$cache" "aa02"
+  create_cache_entry "$cache" "bb01"
+  touch -t 202001010000 "${cache}/bb/bb01-a"
+  echo 1577836800 > "${cache}/trim.txt"
+  mkdir -p "$GOENV_ROOT"
+
+  run goenv-cache stats
+  assert_success
+  assert_line 0 "1.22.5-linux-amd64 (${cache})"
+  assert_line 2 "  entries:    3"
+  assert_line 3 "  used (24h): 2 (66%)"
+  assert_line 4 "  last trim:  2020-01-01"
+}
+
+@test "reports growth since the previous sample" {
+  export GOENV_GOCACHE_DIR="${GOENV_TEST_DIR}/gocache"
+  cache="${GOENV_GOCACHE_DIR}/1.21.0-linux-amd64"
+  create_cache_entry "$cache" "aa01"
+  mkdir -p "$GOENV_ROOT"
+  echo "1577836800 1.21.0-linux-amd64 0 0" > "${GOENV_ROOT}/cache-stats"
+
+  run goenv-cache stats
+  assert_success
+  assert_line 2 "  entries:    1 (+1 since 2020-01-01)"
+  assert_line 4 "  last trim:  never"
+  assert_equal 2 "$(wc -l < "${GOENV_ROOT}/cache-stats" | tr -d ' ')"
+}
+
+@test "reports the default build cache without 'GOENV_GOCACHE_DIR'" {
+  export GOCACHE="${GOENV_TEST_DIR}/go-build"
+  create_cache_entry "$GOCACHE" "aa01"
+
+  run goenv-cache stats
+  assert_success
+  assert_line 0 "default (${GOCACHE})"
+}
+
+@test "reports the size of each cache per version and platform" {
+  export GOENV_GOCACHE_DIR="${GOENV_TEST_DIR}/gocache"
+  export GOENV_GOPATH_PREFIX="${GOENV_TEST_DIR}/go"
+  export GO_BUILD_CACHE_PATH="${GOENV_TEST_DIR}/releases"
+  create_cache_entry "${GOENV_GOCACHE_DIR}/1.22.5-linux-amd64" "aa01"
+  create_cache_entry "${GOENV_GOCACHE_DIR}/1.22.5-linux-arm64" "aa01"
+  mkdir -p "${GOENV_GOPATH_PREFIX}/1.22.5/pkg/mod" "${GOENV_GOPATH_PREFIX}/1.21.0/bin" "$GO_BUILD_CACHE_PATH"
+  touch "${GO_BUILD_CACHE_PATH}/go1.22.5.linux-amd64.tar.gz"
+
+  run goenv-cache size
+  assert_success
+  assert_line 0 "build    1.22.5-linux-amd64 $(du -sk "${GOENV_GOCACHE_DIR}/1.22.5-linux-amd64" | cut -f1)KB ${GOENV_GOCACHE_DIR}/1.22.5-linux-amd64"
+  assert_line 1 "build    1.22.5-linux-arm64 $(du -sk "${GOENV_GOCACHE_DIR}/1.22.5-linux-arm64" | cut -f1)KB ${GOENV_GOCACHE_DIR}/1.22.5-linux-arm64"
+  assert_line 2 "mod      1.22.5             $(du -sk "${GOENV_GOPATH_PREFIX}/1.22.5/pkg/mod" | cut -f1)KB ${GOENV_GOPATH_PREFIX}/1.22.5/pkg/mod"
+  assert_line 3 "releases 1.22.5-linux-amd64 0KB ${GO_BUILD_CACHE_PATH}/go1.22.5.linux-amd64.tar.gz"
+  [[ "${lines[4]}" == "total "*" KB" ]]
+}
+
+@test "fails to report sizes when there are no caches" {
+  GOENV_GOCACHE_DIR="${GOENV_TEST_DIR}/gocache" GOMODCACHE="${GOENV_TEST_DIR}/mod" run goenv-cache size
+  assert_failure "goenv: no Go caches found"
+}
+
+@test "cleans the build caches" {
+  export GOENV_GOCACHE_DIR="${GOENV_TEST_DIR}/gocache"
+  create_cache_entry "${GOENV_GOCACHE_DIR}/1.22.5-linux-amd64" "aa01"
+  create_cache_entry "${GOENV_GOCACHE_DIR}/1.21.0-linux-amd64" "aa01"
+
+  run goenv-cache clean build
+  assert_success
+  [[ "$output" == "Removed "*" from 2 build cache(s)" ]]
+  assert [ ! -e "${GOENV_GOCACHE_DIR}/1.22.5-linux-amd64" ]
+  assert [ ! -e "${GOENV_GOCACHE_DIR}/1.21.0-linux-amd64" ]
+}
+
+@test "cleans only the build cache entries older than the given age" {
+  export GOCACHE="${GOENV_TEST_DIR}/go-build"
+  create_cache_entry "$GOCACHE" "aa01"
+  create_cache_entry "$GOCACHE" "bb01"
+  touch -t 202001010000 "${GOCACHE}/bb/bb01-a" "${GOCACHE}/bb/bb01-d"
+
+  run goenv-cache clean build --older-than 30d
+  assert_success
+  assert [ -e "${GOCACHE}/aa/aa01-a" ]
+  assert [ ! -e "${GOCACHE}/bb/bb01-a" ]
+  assert [ ! -e "${GOCACHE}/bb/bb01-d" ]
+}
+
+@test "cleans the read-only module caches" {
+  export GOMODCACHE="${GOENV_TEST_DIR}/mod"
+  mkdir -p "${GOMODCACHE}/golang.org/x/old@v0.1.0" "${GOMODCACHE}/golang.org/x/new@v0.2.0" "${GOMODCACHE}/cache/download/golang.org/x/old/@v"
+  touch "${GOMODCACHE}/golang.org/x/old@v0.1.0/go.mod" "${GOMODCACHE}/cache/download/golang.org/x/old/@v/v0.1.0.zip"
+  touch -t 202001010000 "${GOMODCACHE}/golang.org/x/old@v0.1.0" "${GOMODCACHE}/cache/download/golang.org/x/old/@v/v0.1.0.zip"
+  chmod -R a-w "${GOMODCACHE}/golang.org"
+
+  run goenv-cache clean mod --older-than 30d
+  assert_success
+  assert [ ! -e "${GOMODCACHE}/golang.org/x/old@v0.1.0" ]
+  assert [ ! -e "${GOMODCACHE}/cache/download/golang.org/x/old/@v/v0.1.0.zip" ]
+  assert [ -d "${GOMODCACHE}/golang.org/x/new@v0.2.0" ]
+
+  run goenv-cache clean mod
+  assert_success
+  assert [ ! -e "$GOMODCACHE" ]
+}
+
+@test "cleans the downloaded releases" {
+  export GO_BUILD_CACHE_PATH="${GOENV_TEST_DIR}/releases"
+  mkdir -p "$GO_BUILD_CACHE_PATH"
+  touch "${GO_BUILD_CACHE_PATH}/go1.22.5.linux-amd64.tar.gz" "${GO_BUILD_CACHE_PATH}/go1.21.0.linux-amd64.tar.gz"
+  touch -t 202001010000 "${GO_BUILD_CACHE_PATH}/go1.21.0.linux-amd64.tar.gz"
+
+  run goenv-cache clean releases --older-than 30d
+  assert_success
+  assert [ -e "${GO_BUILD_CACHE_PATH}/go1.22.5.linux-amd64.tar.gz" ]
+  assert [ ! -e "${GO_BUILD_CACHE_PATH}/go1.21.0.linux-amd64.tar.gz" ]
+}
+
+@test "fails to clean with an invalid age" {
+  run goenv-cache clean build --older-than 30
+  assert_failure "goenv: invalid age '30', give it in days, e.g. '30d'"
+}
+
+@test "prints the caches of the selected version" {
+  export GOENV_GOCACHE_DIR="${GOENV_TEST_DIR}/gocache"
+  export GOENV_GOPATH_PREFIX="${GOENV_TEST_DIR}/go"
+  export GO_BUILD_CACHE_PATH="${GOENV_TEST_DIR}/releases"
+  create_version "1.22.5"
+  GOENV_VERSION=1.22.5 run goenv-cache path
+  assert_success_out <<OUT
+build    ${GOENV_GOCACHE_DIR}/1.22.5-$(goenv-cache --platform)
+mod      ${GOENV_GOPATH_PREFIX}/1.22.5/pkg/mod
+releases ${GO_BUILD_CACHE_PATH}
+OUT
+
+  GOENV_VERSION=1.22.5 run goenv-cache path mod
+  assert_success "${GOENV_GOPATH_PREFIX}/1.22.5/pkg/mod"
+}
@@ -0,0 +1,60 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+@test "has usage instructions" {
+  run goenv-help --usage cgo-check
+  assert_success "Usage: goenv cgo-check [--probe]"
+}
+
+@test "has completion support" {
+  run goenv-cgo-check --complete
+  assert_success "--probe"
+}
+
+@test "fails with usage instructions when unknown argument is given" {
+  run goenv-cgo-check --nope
+  assert_failure "Usage: goenv cgo-check [--probe]"
+}
+
+@test "prints path of the C compiler from 'CC' when it is found" {
+  create_executable "${GOENV_TEST_DIR}/bin" "fakecc" "#!/bin/sh"
+
+  CC="fakecc -O2" run goenv-cgo-check
+
+  assert_success "${GOENV_TEST_DIR}/bin/fakecc"
+}
+
+@test "fails with install instructions when the C compiler is not found" {
+  CC=does-not-exist-cc run goenv-cgo-check
+
+  assert_failure
+  assert_line 0 "goenv: cgo is enabled but the C compiler 'does-not-exist-cc' was not found"
+  assert_line 1 "To install one, run:"
+  assert_line 3 "Or set CGO_ENABLED=0 to build without cgo."
+}
+
+@test "fails when '--probe' is given and the C compiler cannot compile" {
+  create_executable "${GOENV_TEST_DIR}/bin" "fakecc" <<SH
+#!/bin/sh
+echo "fakecc: cannot find crt1.o"
+exit 1
+SH
+
+  CC=fakecc run goenv-cgo-check --probe
+
+  assert_failure
+  assert_line 0 "goenv: the C compiler 'fakecc' cannot compile a trivial program:"
+  assert_line 1 "  fakecc: cannot find crt1.o"
+}
+
+@test "succeeds when '--probe' is given and the C compiler can compile" {
+  create_executable "${GOENV_TEST_DIR}/bin" "fakecc" <<SH
+#!/bin/sh
+: > "\$2"
+SH
+
+  CC=fakecc run goenv-cgo-check --probe
+
+  assert_success "${GOENV_TEST_DIR}/bin/fakecc"
+}
@@ -26,24 +26,54 @@ OUT
 
   assert_success "1.10.1
 1.9.2
+atomic-write
+bake
+cache
+cgo-check
 commands
 completions
+doctor
+env
+env-vars
 exec
+export
+first-run
+format
+generate-scope
+gitignore
 global
 help
+hook
 hooks
 init
 install
 installed
 latest
 local
+logs
+migrate
+migrate-arch
+org-defaults
 prefix
+prompt
+prune
+query
 rehash
+repro
 root
+run
+sbom
+setup
 shell
 shims
+stats
+suggest
 system
+telemetry
+tools
 uninstall
+update
+verify
 version
 version-file
 version-file-read
@@ -51,13 +81,16 @@ version-file-write
 version-name
 version-origin
 versions
+watch
+watchd
 whence
 which"
 }
 
 @test "'commands --sh' returns only commands containing 'sh'" {
   run goenv-commands --sh
-  assert_success "rehash
+  assert_success "hook
+rehash
 shell"
 
   refute_line "commands"
@@ -90,9 +123,21 @@ shell"
   run goenv-commands --no-sh
   assert_success "1.10.1
 1.9.2
+atomic-write
+bake
+cache
+cgo-check
 commands
 completions
+doctor
+env
+env-vars
 exec
+export
+first-run
+format
+generate-scope
+gitignore
 global
 help
 hooks
@@ -101,12 +146,29 @@ install
 installed
 latest
 local
+logs
+migrate
+migrate-arch
+org-defaults
 prefix
+prompt
+prune
+query
 rehash
+repro
 root
+run
+sbom
+setup
 shims
+stats
+suggest
 system
+telemetry
+tools
 uninstall
+update
+verify
 version
 version-file
 version-file-read
@@ -114,6 +176,8 @@ version-file-write
 version-name
 version-origin
 versions
+watch
+watchd
 whence
 which"
 
@@ -0,0 +1,671 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+@test "has usage instructions" {
+  run goenv-help --usage doctor
+  assert_success_out <<OUT
+Usage: goenv doctor [--json|--fix [--dry-run]] [--network]
+       goenv doctor --compare <baseline.json> [--network]
+OUT
+}
+
+@test "fails and prints usage when unknown arguments are given" {
+  run goenv-doctor --nope
+  assert_failure
+  assert_line 0 "Usage: goenv doctor [--json|--fix [--dry-run]] [--network]"
+}
+
+@test "reports error when 'GOENV_ROOT' does not exist" {
+  run goenv-doctor
+
+  assert_failure
+  assert_line 0 "[ERROR] GOENV_ROOT '${GOENV_ROOT}' does not exist, run 'goenv init' to create it"
+}
+
+@test "reports every check as ok when 'GOENV_ROOT' is set up and shims are in PATH" {
+  mkdir -p "${GOENV_ROOT}/versions" "${GOENV_ROOT}/shims"
+  create_executable "${GOENV_TEST_DIR}/bin" "fakecc" <<SH
+#!/bin/sh
+: > "\$2"
+SH
+
+  CC=fakecc run goenv-doctor
+
+  assert_success_out <<OUT
+[OK]    GOENV_ROOT is '${GOENV_ROOT}'
+[OK]    '${GOENV_ROOT}/versions' is readable and writable
+[OK]    shims are kept in '${GOENV_ROOT}/shims'
+[OK]    '${GOENV_ROOT}/shims' is in PATH
+[OK]    everything in GOENV_ROOT is owned by $(id -un)
+[OK]    a single goenv installation is in PATH
+[OK]    C compiler '${GOENV_TEST_DIR}/bin/fakecc' works, cgo builds are possible
+OUT
+}
+
+@test "warns when no working C compiler is found" {
+  mkdir -p "${GOENV_ROOT}/versions"
+
+  CC=does-not-exist-cc run goenv-doctor
+
+  assert_success
+  assert_line "[WARN]  no working C compiler found, cgo builds will fail (run 'goenv cgo-check --probe' for details)"
+}
+
+@test "warns when no versions directory exists" {
+  mkdir -p "${GOENV_ROOT}"
+
+  run goenv-doctor
+
+  assert_success
+  assert_line 1 "[WARN]  '${GOENV_ROOT}/versions' does not exist, no Go versions are installed"
+}
+
+@test "reports per-user shims directory from 'GOENV_SHIMS_DIR'" {
+  mkdir -p "${GOENV_ROOT}/versions"
+  export GOENV_SHIMS_DIR="${HOME}/.local/share/goenv/shims"
+
+  run goenv-doctor
+
+  assert_success
+  assert_line 2 "[OK]    shims are kept per user in '${GOENV_SHIMS_DIR}'"
+  assert_line 3 "[WARN]  '${GOENV_SHIMS_DIR}' is not in PATH, add 'eval \"\$(goenv init -)\"' to your shell profile"
+}
+
+@test "reports error when shims directory is not writable" {
+  if [ "$(whoami)" = "root" ]; then
+    skip "running as root. permissions won't matter."
+  fi
+
+  mkdir -p "${GOENV_ROOT}/versions" "${GOENV_ROOT}/shims"
+  chmod 0555 "${GOENV_ROOT}/shims"
+
+  run goenv-doctor
+
+  chmod 0755 "${GOENV_ROOT}/shims"
+  assert_failure
+  assert_line 2 "[ERROR] '${GOENV_ROOT}/shims' is not writable by $(id -un), set GOENV_SHIMS_DIR to a directory you own"
+}
+
+@test "prints results as JSON with --json" {
+  mkdir -p "${GOENV_ROOT}/versions" "${GOENV_ROOT}/shims"
+  CC=does-not-exist-cc run goenv-doctor --json
+
+  assert_success_out <<OUT
+[
+  {"check": "root", "status": "ok", "message": "GOENV_ROOT is '${GOENV_ROOT}'"},
+  {"check": "versions-dir", "status": "ok", "message": "'${GOENV_ROOT}/versions' is readable and writable"},
+  {"check": "shims-dir", "status": "ok", "message": "shims are kept in '${GOENV_ROOT}/shims'"},
+  {"check": "shims-path", "status": "ok", "message": "'${GOENV_ROOT}/shims' is in PATH"},
+  {"check": "ownership", "status": "ok", "message": "everything in GOENV_ROOT is owned by $(id -un)"},
+  {"check": "installations", "status": "ok", "message": "a single goenv installation is in PATH"},
+  {"check": "cgo-compiler", "status": "warning", "message": "no working C compiler found, cgo builds will fail (run 'goenv cgo-check --probe' for details)"}
+]
+OUT
+}
+
+@test "fails with --compare when the baseline can't be read" {
+  run goenv-doctor --compare "${GOENV_TEST_DIR}/missing.json"
+  assert_failure "goenv: cannot read baseline '${GOENV_TEST_DIR}/missing.json'"
+}
+
+@test "reports no changes against an identical baseline" {
+  mkdir -p "${GOENV_ROOT}/versions"
+  export CC=does-not-exist-cc
+  goenv-doctor --json > "${GOENV_TEST_DIR}/baseline.json"
+
+  run goenv-doctor --compare "${GOENV_TEST_DIR}/baseline.json"
+  assert_success "No changes since baseline '${GOENV_TEST_DIR}/baseline.json'"
+}
+
+@test "reports resolved issues against a baseline" {
+  export CC=does-not-exist-cc
+  goenv-doctor --json > "${GOENV_TEST_DIR}/baseline.json" || true
+  mkdir -p "${GOENV_ROOT}/versions"
+
+  run goenv-doctor --compare "${GOENV_TEST_DIR}/baseline.json"
+  assert_success_out <<OUT
+Resolved issues:
+  [ERROR] GOENV_ROOT '${GOENV_ROOT}' does not exist, run 'goenv init' to create it
+  [WARN]  '${GOENV_ROOT}/versions' does not exist, no Go versions are installed
+OUT
+}
+
+@test "fails on new issues against a baseline" {
+  mkdir -p "${GOENV_ROOT}/versions" "${GOENV_ROOT}/shims"
+  export CC=does-not-exist-cc
+  goenv-doctor --json > "${GOENV_TEST_DIR}/baseline.json"
+  rmdir "${GOENV_ROOT}/versions"
+
+  run goenv-doctor --compare "${GOENV_TEST_DIR}/baseline.json"
+  assert_failure_out <<OUT
+New issues:
+  [WARN]  '${GOENV_ROOT}/versions' does not exist, no Go versions are installed
+OUT
+}
+
+@test "lists changed issues against a baseline" {
+  mkdir -p "${GOENV_ROOT}"
+  export CC=does-not-exist-cc
+  cat > "${GOENV_TEST_DIR}/baseline.json" <<JSON
+[
+  {"check": "versions-dir", "status": "warning", "message": "'/old/versions' does not exist"}
+]
+JSON
+
+  run goenv-doctor --compare "${GOENV_TEST_DIR}/baseline.json"
+  assert_failure
+  assert_line 0 "New issues:"
+  assert_line 1 "  [WARN]  no working C compiler found, cgo builds will fail (run 'goenv cgo-check --probe' for details)"
+  assert_line 2 "Changed issues:"
+  assert_line 3 "  [WARN]  '/old/versions' does not exist"
+  assert_line 4 "    -> [WARN]  '${GOENV_ROOT}/versions' does not exist, no Go versions are installed"
+}
+
+@test "leaves checks that didn't run out of the comparison against a baseline" {
+  mkdir -p "${GOENV_ROOT}/versions"
+  export CC=does-not-exist-cc
+  goenv-doctor --json | sed 's|^]$|  {"check": "goproxy", "status": "error", "message": "GOPROXY is not reachable: https://proxy.golang.org, modules cannot be downloaded"}\n]|' > "${GOENV_TEST_DIR}/baseline.json"
+
+  run goenv-doctor --compare "${GOENV_TEST_DIR}/baseline.json"
+  assert_success "No changes since baseline '${GOENV_TEST_DIR}/baseline.json'"
+}
+
+@test "escapes control characters in JSON messages" {
+  export GOENV_ROOT="${GOENV_TEST_DIR}/go"$'\t'"env"
+  export CC=does-not-exist-cc
+  goenv-doctor --json > "${GOENV_TEST_DIR}/baseline.json" || true
+
+  run cat "${GOENV_TEST_DIR}/baseline.json"
+  assert_line '  {"check": "root", "status": "error", "message": "GOENV_ROOT '"'${GOENV_TEST_DIR}/go\\tenv'"' does not exist, run '"'goenv init'"' to create it"},'
+
+  run goenv-doctor --compare "${GOENV_TEST_DIR}/baseline.json"
+  assert_success "No changes since baseline '${GOENV_TEST_DIR}/baseline.json'"
+}
+
+@test "warns about multiple goenv installations in PATH" {
+  mkdir -p "${GOENV_ROOT}/versions"
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv" "#!/bin/sh"
+
+  run goenv-doctor
+
+  assert_success
+  assert_line "[WARN]  multiple goenv installations found in PATH: $(command -v goenv) ${GOENV_TEST_DIR}/bin/goenv, remove all but one"
+}
+
+@test "reports shims created by a goenv that no longer exists" {
+  mkdir -p "${GOENV_ROOT}/versions" "${GOENV_ROOT}/shims"
+  cat > "${GOENV_ROOT}/shims/go" <<SH
+#!/usr/bin/env bash
+exec "/opt/homebrew/Cellar/goenv/1.0.0/libexec/goenv" exec "\$program" "\$@"
+SH
+
+  run goenv-doctor
+
+  assert_failure
+  assert_line "[ERROR] shims in '${GOENV_ROOT}/shims' run '/opt/homebrew/Cellar/goenv/1.0.0/libexec/goenv', which no longer exists, run 'goenv rehash'"
+}
+
+@test "warns about shims in an older format" {
+  mkdir -p "${GOENV_ROOT}/versions" "${GOENV_ROOT}/shims"
+  cat > "${GOENV_ROOT}/shims/go" <<SH
+#!/usr/bin/env bash
+exec goenv exec go "\$@"
+SH
+
+  run goenv-doctor
+
+  assert_success
+  assert_line "[WARN]  shims in '${GOENV_ROOT}/shims' were created by an older goenv, run 'goenv rehash'"
+}
+
+@test "reports shims created by this goenv installation as ok" {
+  create_executable "1.22.5" "go"
+  goenv-rehash
+
+  run goenv-doctor
+
+  assert_line "[OK]    shims were created by this goenv installation"
+}
+
+@test "warns about files in 'GOENV_ROOT' owned by other users" {
+  if [ "$(id -u)" != "0" ]; then
+    skip "needs root to create files owned by another user"
+  fi
+
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5"
+  chown nobody "${GOENV_ROOT}/versions/1.22.5"
+
+  run goenv-doctor
+
+  assert_success
+  assert_line "[WARN]  files in GOENV_ROOT are owned by other users, e.g. '${GOENV_ROOT}/versions/1.22.5' (after 'sudo goenv install'?), run 'goenv doctor --fix'"
+}
+
+@test "repairs ownership of 'GOENV_ROOT' with --fix after confirmation" {
+  if [ "$(id -u)" != "0" ]; then
+    skip "needs root to create files owned by another user"
+  fi
+
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5"
+  chown nobody "${GOENV_ROOT}/versions/1.22.5"
+
+  run goenv-doctor --fix <<< "n"
+  assert_success
+  assert_line "Skipped."
+  assert_equal "nobody" "$(stat -c %U "${GOENV_ROOT}/versions/1.22.5" 2>/dev/null || stat -f %Su "${GOENV_ROOT}/versions/1.22.5")"
+
+  run goenv-doctor --fix <<< "y"
+  assert_success
+  assert_equal "$(id -un)" "$(stat -c %U "${GOENV_ROOT}/versions/1.22.5" 2>/dev/null || stat -f %Su "${GOENV_ROOT}/versions/1.22.5")"
+}
+
+@test "repairs ownership of 'GOENV_ROOT' with --fix as answered in 'GOENV_ANSWERS'" {
+  if [ "$(id -u)" != "0" ]; then
+    skip "needs root to create files owned by another user"
+  fi
+
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5"
+  chown nobody "${GOENV_ROOT}/versions/1.22.5"
+  echo "doctor.fix-ownership: y" > "${GOENV_TEST_DIR}/answers.yaml"
+
+  GOENV_ANSWERS="${GOENV_TEST_DIR}/answers.yaml" run goenv-doctor --fix < /dev/null
+  assert_success
+  assert_line "Change the owner of everything in '${GOENV_ROOT}' to $(id -un):$(id -gn)? (y/N) y"
+  assert_equal "$(id -un)" "$(stat -c %U "${GOENV_ROOT}/versions/1.22.5" 2>/dev/null || stat -f %Su "${GOENV_ROOT}/versions/1.22.5")"
+}
+
+@test "does not offer to repair anything with --fix when there are no problems" {
+  mkdir -p "${GOENV_ROOT}/versions"
+
+  run goenv-doctor --fix < /dev/null
+
+  assert_success
+  refute_line "Skipped."
+}
+
+@test "installs the selected version with --fix when it isn't installed" {
+  mkdir -p "${GOENV_ROOT}/versions"
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv-install" <<SH
+#!/bin/sh
+echo "goenv-install \$*"
+SH
+
+  GOENV_VERSION=1.22.5 run goenv-doctor --fix <<< "y"
+
+  assert_failure
+  assert_line "[ERROR] Go 1.22.5 is selected by 'GOENV_VERSION environment variable', but not installed, run 'goenv install 1.22.5'"
+  assert_line "goenv-install --skip-existing 1.22.5"
+}
+
+@test "prints what --fix would repair with --dry-run without changing anything" {
+  mkdir -p "${GOENV_ROOT}/versions" "${GOENV_ROOT}/shims"
+  cat > "${GOENV_ROOT}/shims/go" <<SH
+#!/nonexistent/bash
+SH
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv-install" <<SH
+#!/bin/sh
+echo "goenv-install \$*"
+SH
+
+  GOENV_VERSION=1.22.5 run goenv-doctor --fix --dry-run < /dev/null
+
+  assert_failure
+  assert_line "Would install Go 1.22.5."
+  assert_line "Would create the shims again with 'goenv rehash'."
+  refute_line "goenv-install --skip-existing 1.22.5"
+  assert_equal "#!/nonexistent/bash" "$(head -1 "${GOENV_ROOT}/shims/go")"
+}
+
+@test "syncs the toolchain of go.mod to '.go-version' with --fix" {
+  mkdir -p "${GOENV_ROOT}/versions/1.23.4" "${GOENV_TEST_DIR}/myproject"
+  cd "${GOENV_TEST_DIR}/myproject"
+  echo "1.23.4" > .go-version
+  printf 'module example.com/myproject\n\ngo 1.22\n\ntoolchain go1.22.5\n' > go.mod
+
+  run goenv-doctor --fix <<< "y"
+
+  assert_line "[WARN]  the toolchain of '${GOENV_TEST_DIR}/myproject/go.mod' (1.22.5) is not the version in '${GOENV_TEST_DIR}/myproject/.go-version' (1.23.4), run 'goenv local --sync-gomod'"
+  assert_equal "toolchain go1.23.4" "$(tail -1 go.mod)"
+}
+
+@test "fails and prints usage when --dry-run is given without --fix" {
+  run goenv-doctor --dry-run
+  assert_failure
+  assert_line 0 "Usage: goenv doctor [--json|--fix [--dry-run]] [--network]"
+}
+
+@test "reports a shims directory on a file system mounted noexec" {
+  mkdir -p "${GOENV_ROOT}/versions" "${GOENV_ROOT}/shims"
+  create_executable "${GOENV_TEST_DIR}/bin" "findmnt" <<SH
+#!/usr/bin/env bash
+case "\${@: -1}" in
+"${GOENV_ROOT}"* ) echo "rw,nosuid,nodev,noexec,relatime" ;;
+* ) echo "rw,relatime" ;;
+esac
+SH
+
+  run goenv-doctor
+
+  assert_failure
+  assert_line "[ERROR] '${GOENV_ROOT}/shims' is on a file system mounted noexec, so shims cannot run, run 'goenv doctor --fix' to move them to '${HOME}/.local/share/goenv/shims'"
+}
+
+@test "suggests 'GOENV_LOCAL_DIR' for a GOENV_ROOT on a network share" {
+  mkdir -p "${GOENV_ROOT}/versions" "${GOENV_ROOT}/shims"
+  create_executable "${GOENV_TEST_DIR}/bin" "findmnt" <<SH
+#!/usr/bin/env bash
+case "\$*:\${@: -1}" in
+*FSTYPE*:"${GOENV_ROOT}"* ) echo "nfs4" ;;
+*FSTYPE* ) echo "ext4" ;;
+* ) echo "rw,relatime" ;;
+esac
+SH
+
+  run goenv-doctor
+  assert_line "[WARN]  '${GOENV_ROOT}' is on a network share (nfs4), set GOENV_LOCAL_DIR to a directory on a local disk to keep the shims, the Go build cache and an offline copy of Go there"
+
+  GOENV_LOCAL_DIR="${GOENV_TEST_DIR}/local" GOENV_SHIMS_DIR="${GOENV_TEST_DIR}/local/shims" run goenv-doctor
+  assert_line "[OK]    '${GOENV_ROOT}' is on a network share (nfs4), the shims and caches are kept in '${GOENV_TEST_DIR}/local'"
+}
+
+@test "moves shims off a file system mounted noexec with --fix" {
+  create_executable "1.22.5" "go"
+  create_executable "${GOENV_TEST_DIR}/bin" "findmnt" <<SH
+#!/usr/bin/env bash
+case "\${@: -1}" in
+"${GOENV_ROOT}"* ) echo "rw,noexec" ;;
+* ) echo "rw" ;;
+esac
+SH
+
+  run goenv-doctor --fix <<< "y"
+
+  assert_line "Shims were created in '${HOME}/.local/share/goenv/shims'. To use them, add this to your shell's startup file, before 'goenv init':"
+  assert_line "  export GOENV_SHIMS_DIR=\"${HOME}/.local/share/goenv/shims\""
+  assert [ -x "${HOME}/.local/share/goenv/shims/go" ]
+}
+
+@test "reports shims whose interpreter does not exist" {
+  mkdir -p "${GOENV_ROOT}/versions" "${GOENV_ROOT}/shims"
+  cat > "${GOENV_ROOT}/shims/go" <<SH
+#!/nonexistent/bash
+exec goenv exec go "\$@"
+SH
+
+  run goenv-doctor
+
+  assert_failure
+  assert_line "[ERROR] shims in '${GOENV_ROOT}/shims' run with '/nonexistent/bash', but '/nonexistent/bash' does not exist, run 'goenv rehash'"
+}
+
+@test "reports the interpreter of shims as ok when it exists" {
+  create_executable "1.22.5" "go"
+  goenv-rehash
+
+  run goenv-doctor
+
+  assert_line "[OK]    shims run with '/usr/bin/env bash'"
+}
+
+@test "reports a shell that runs 'go' from a removed version" {
+  mkdir -p "${GOENV_ROOT}/versions"
+
+  GOENV_SHELL=zsh GOENV_HASHED_GO="${GOENV_ROOT}/versions/1.21.0/bin/go" run goenv-doctor
+
+  assert_failure
+  assert_line "[ERROR] the shell runs 'go' from '${GOENV_ROOT}/versions/1.21.0/bin/go', which no longer exists, run 'rehash'"
+}
+
+@test "warns about a shell that runs another 'go' than the one in PATH" {
+  create_executable "1.22.5" "go"
+  goenv-rehash
+  mkdir -p "${GOENV_TEST_DIR}/bin"
+  touch "${GOENV_TEST_DIR}/bin/go"
+  chmod +x "${GOENV_TEST_DIR}/bin/go"
+
+  GOENV_SHELL=bash GOENV_HASHED_GO="${GOENV_TEST_DIR}/bin/go" run goenv-doctor
+
+  assert_line "[WARN]  the shell runs 'go' from '${GOENV_TEST_DIR}/bin/go' instead of '${GOENV_ROOT}/shims/go', run 'hash -r'"
+}
+
+@test "warns about a log that grew over the size limit" {
+  mkdir -p "${GOENV_ROOT}/versions" "${GOENV_ROOT}/watchd"
+  head -c 4096 /dev/zero > "${GOENV_ROOT}/watchd/log"
+
+  GOENV_LOG_MAX_SIZE=2 run goenv-doctor
+
+  assert_line "[WARN]  the watchd log has grown to 4 KB, over GOENV_LOG_MAX_SIZE (2 KB), run 'goenv logs clean'"
+}
+
+@test "warns about versions whose telemetry mode differs from 'goenv telemetry'" {
+  create_executable "1.24.0" "go" <<SH
+#!/bin/sh
+echo "on"
+SH
+  echo "off" > "${GOENV_ROOT}/telemetry"
+
+  run goenv-doctor
+
+  assert_line "[WARN]  Go telemetry is not 'off' as set with 'goenv telemetry' for: 1.24.0, run 'goenv telemetry off'"
+
+  rm "${GOENV_ROOT}/telemetry"
+  run goenv-doctor
+
+  assert_line "[OK]    Go telemetry: 1.24.0 is 'on'"
+}
+
+@test "warns about versions installed for another C library" {
+  [ "$(uname -s)" = "Linux" ] || skip "needs Linux"
+  create_executable "${GOENV_TEST_DIR}/bin" "ldd" <<SH
+#!/bin/sh
+echo "ldd (GNU libc) 2.39"
+SH
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5" "${GOENV_ROOT}/versions/1.23.1"
+  echo "libc=musl 1.2.4" > "${GOENV_ROOT}/versions/1.22.5/.goenv-metadata"
+  echo "libc=glibc 2.39" > "${GOENV_ROOT}/versions/1.23.1/.goenv-metadata"
+
+  run goenv-doctor
+
+  assert_line "[WARN]  Go versions installed on a system with another C library than glibc: 1.22.5, reinstall them with 'goenv install --force <version>'"
+
+  rm "${GOENV_ROOT}/versions/1.22.5/.goenv-metadata"
+  run goenv-doctor
+
+  assert_line "[OK]    Go versions were installed for glibc"
+}
+
+@test "warns about tools declared in go.mod that are not installed" {
+  mkdir -p "${GOENV_ROOT}/versions/1.24.0" "${GOENV_TEST_DIR}/app"
+  cd "${GOENV_TEST_DIR}/app"
+  printf 'module example.com/app\n\ngo 1.24\n\ntool golang.org/x/tools/cmd/stringer\n' > go.mod
+  echo "1.24.0" > .go-version
+
+  run goenv-doctor
+
+  assert_line "[WARN]  tools declared in go.mod are not installed: stringer, run 'goenv tools sync --from-gomod'"
+}
+
+@test "warns about shims that run other versions of tools declared in go.mod" {
+  mkdir -p "${GOENV_TEST_DIR}/app"
+  cd "${GOENV_TEST_DIR}/app"
+  printf 'module example.com/app\n\ngo 1.24\n\nrequire golang.org/x/tools v0.20.0 // indirect\n\ntool golang.org/x/tools/cmd/stringer\n' > go.mod
+  echo "1.24.0" > .go-version
+  create_executable "1.24.0" "go" <<SH
+#!/bin/sh
+printf '%s: go1.24.0\\n\\tpath\\tgolang.org/x/tools/cmd/stringer\\n\\tmod\\tgolang.org/x/tools\\tv0.19.0\\th1:x\\n' "\$3"
+SH
+  create_executable "${HOME}/go/1.24.0/bin" "stringer" "#!/bin/sh"
+  create_executable "${GOENV_ROOT}/shims" "stringer" "#!/bin/sh"
+
+  run goenv-doctor
+
+  assert_line "[WARN]  shims run other versions of tools declared in go.mod: stringer (v0.19.0, go.mod requires v0.20.0), run them with 'go tool <name>' or run 'goenv tools sync --from-gomod'"
+
+  sed -i.bak 's/v0.20.0/v0.19.0/' go.mod
+  run goenv-doctor
+
+  assert_line "[OK]    shims run the versions of the tools declared in go.mod that it requires"
+}
+
+@test "warns about tools listed in .goenv-tools that are not installed" {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.0" "${GOENV_TEST_DIR}/app"
+  cd "${GOENV_TEST_DIR}/app"
+  echo "golang.org/x/tools/gopls@latest" > .goenv-tools
+  echo "1.22.0" > .go-version
+
+  run goenv-doctor
+
+  assert_line "[WARN]  tools listed in .goenv-tools are missing or at other versions: gopls, run 'goenv tools install'"
+
+  create_executable "${HOME}/go/1.22.0/bin" "gopls" <<SH
+#!/bin/sh
+SH
+  run goenv-doctor
+
+  assert_line "[OK]    tools listed in .goenv-tools are installed"
+}
+
+@test "fails and prints usage when --json and --fix are given together" {
+  run goenv-doctor --json --fix
+  assert_failure
+  assert_line 0 "Usage: goenv doctor [--json|--fix [--dry-run]] [--network]"
+}
+
+@test "reports over which address families the download server is reachable with --network" {
+  mkdir -p "${GOENV_ROOT}/versions"
+  create_executable "${GOENV_TEST_DIR}/bin" "curl" <<SH
+#!/bin/sh
+case " \$* " in
+*" -6 "* | *" https://proxy.golang.org "*) exit 0 ;;
+*) exit 7 ;;
+esac
+SH
+  unset GOPROXY
+
+  run goenv-doctor --network
+  assert_success
+  assert_line "[OK]    'https://go.dev/dl/' is reachable over IPv6 only"
+  assert_line "[OK]    GOPROXY is reachable: 'https://proxy.golang.org'"
+
+  run goenv-doctor
+  refute_line "[OK]    'https://go.dev/dl/' is reachable over IPv6 only"
+}
+
+@test "reports error when the download server is not reachable with --network" {
+  mkdir -p "${GOENV_ROOT}/versions"
+  create_executable "${GOENV_TEST_DIR}/bin" "curl" <<SH
+#!/bin/sh
+exit 7
+SH
+  export GO_BUILD_MIRROR_URL="https://mirror.example.com/go/" GOPROXY=off
+
+  run goenv-doctor --network --json
+  assert_failure
+  assert_line '  {"check": "network", "status": "error", "message": "'"'"'https://mirror.example.com/go/'"'"' is not reachable over IPv4 or IPv6 (cannot connect to it), check your network and proxy settings"}'
+}
+
+@test "reports which GOPROXY entry is not reachable with --network" {
+  mkdir -p "${GOENV_ROOT}/versions"
+  create_executable "${GOENV_TEST_DIR}/bin" "curl" <<SH
+#!/bin/sh
+case " \$* " in
+*" https://proxy.example.com "*) exit 6 ;;
+esac
+SH
+  export GOPROXY="https://proxy.example.com|https://proxy.golang.org,direct"
+
+  run goenv-doctor --network
+  assert_failure
+  assert_line "[OK]    'https://go.dev/dl/' is reachable over IPv4 and IPv6"
+  assert_line "[ERROR] GOPROXY is not reachable: 'https://proxy.example.com' (cannot resolve its host), modules cannot be downloaded"
+}
+
+@test "tells which proxy is used unless NO_PROXY lists the host with --network" {
+  mkdir -p "${GOENV_ROOT}/versions"
+  create_executable "${GOENV_TEST_DIR}/bin" "curl" "#!/bin/sh"
+  export GOPROXY="https://goproxy.corp.internal,https://proxy.golang.org"
+  export HTTPS_PROXY="http://proxy.corp:3128" NO_PROXY="localhost,.internal"
+
+  run goenv-doctor --network
+  assert_success
+  assert_line "[OK]    'https://go.dev/dl/' is reachable over IPv4 and IPv6 through the proxy 'http://proxy.corp:3128'"
+  assert_line "[OK]    GOPROXY is reachable: 'https://goproxy.corp.internal', 'https://proxy.golang.org' through the proxy 'http://proxy.corp:3128'"
+}
+
+@test "detects a proxy that intercepts HTTPS with --network" {
+  mkdir -p "${GOENV_ROOT}/versions"
+  create_executable "${GOENV_TEST_DIR}/bin" "curl" <<SH
+#!/bin/sh
+case " \$* " in
+*" -qskv "*) echo "*  issuer: CN=Corp Inspection CA" >&2 ;;
+*) exit 60 ;;
+esac
+SH
+  export GOPROXY=off
+
+  run goenv-doctor --network
+  assert_failure
+  assert_line "[ERROR] 'https://go.dev/dl/' is not reachable over IPv4 or IPv6 (its certificate, issued by 'CN=Corp Inspection CA', isn't trusted, a proxy or VPN may be intercepting HTTPS, add its CA certificate to the system's or set SSL_CERT_FILE), check your network and proxy settings"
+}
+
+@test "warns when the minor version of the selected Go reached its end of life" {
+  create_executable "1.20.14" "go" "#!/bin/sh"
+  create_executable "1.22.5" "go" "#!/bin/sh"
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv-install" <<SH
+#!/bin/sh
+echo "Available versions:"
+echo "  1.20.14"
+echo "  1.21.12"
+echo "  1.22.5"
+SH
+
+  GOENV_VERSION=1.20.14 run goenv-doctor
+  assert_success
+  assert_line "[WARN]  go 1.20, selected by 'GOENV_VERSION environment variable', reached its end of life and doesn't receive security fixes, upgrade to go 1.22"
+
+  GOENV_VERSION=1.22.5 run goenv-doctor
+  assert_success
+  assert_line "[OK]    go 1.22 receives security fixes until go 1.24 is released"
+}
+
+@test "warns about amd64 Go versions on Apple Silicon" {
+  create_executable "1.20.5" "go" <<SH
+#!/bin/sh
+# x86_64
+SH
+  create_executable "${GOENV_TEST_DIR}/bin" "uname" <<SH
+#!/bin/sh
+[ "\$1" = "-s" ] && echo "Darwin" || echo "arm64"
+SH
+  create_executable "${GOENV_TEST_DIR}/bin" "file" <<SH
+#!/bin/sh
+grep -q x86_64 "\$2" && echo "Mach-O 64-bit executable x86_64" || echo "Mach-O 64-bit executable arm64"
+SH
+
+  run goenv-doctor
+
+  assert_line "[WARN]  Go versions or tools built for amd64 run emulated by Rosetta: 1.20.5, run 'goenv migrate-arch'"
+}
+
+@test "reports a go from Nix that comes before the shims in PATH" {
+  mkdir -p "${GOENV_ROOT}/versions" "${GOENV_ROOT}/shims"
+  create_executable "${HOME}/.nix-profile/bin" "go" "#!/bin/sh"
+
+  PATH="${HOME}/.nix-profile/bin:${GOENV_ROOT}/shims:${PATH}" run goenv-doctor
+
+  assert_line "[ERROR] '${HOME}/.nix-profile/bin/go' from Nix comes before the shims in PATH, so goenv is bypassed; move '${GOENV_ROOT}/shims' before it, or pin the goenv version in Nix with 'goenv export nix'"
+}
+
+@test "warns about a GOENV_ROOT owned by another user in a container" {
+  if [ "$(id -u)" != "0" ]; then
+    skip "needs root to create files owned by another user"
+  fi
+  mkdir -p "${GOENV_ROOT}/versions"
+  chown nobody "$GOENV_ROOT"
+  owner="$(stat -c %u "$GOENV_ROOT" 2>/dev/null || stat -f %u "$GOENV_ROOT")"
+
+  container=docker GOENV_UID_CACHE_DIR=/tmp/goenv-uid run goenv-doctor
+
+  assert_line "[WARN]  GOENV_ROOT is owned by UID ${owner}, but the container runs as UID 0; goenv exec keeps the Go caches in '/tmp/goenv-uid' instead, run the container as UID ${owner} to share them"
+}
@@ -0,0 +1,64 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+@test "has usage instructions" {
+  run goenv-help --usage env-vars
+  assert_success "Usage: goenv env-vars [--json]"
+}
+
+@test "has completion support" {
+  run goenv-env-vars --complete
+  assert_success "--json"
+}
+
+@test "fails and prints usage with unknown arguments" {
+  run goenv-env-vars --yaml
+  assert_failure "Usage: goenv env-vars [--json]"
+}
+
+@test "lists each variable with its default and description" {
+  run goenv-env-vars
+  assert_success
+  assert_line 0 "GOENV_VERSION"
+  assert_line 1 "  Specifies the Go version to be used. Also see 'goenv help shell'."
+  assert_line "GOENV_ROOT (default: ~/.goenv)"
+}
+
+@test "lists the variables as JSON with '--json'" {
+  run goenv-env-vars --json
+  assert_success
+  assert_line 0 "["
+  assert_line 1 "  {\"name\": \"GOENV_VERSION\", \"default\": null, \"description\": \"Specifies the Go version to be used. Also see 'goenv help shell'.\"},"
+  assert_line "  {\"name\": \"GOENV_INSTALL_JOBS\", \"default\": \"4\", \"description\": \"Number of versions 'goenv install' installs at a time when given several. Also see 'goenv help install'.\"},"
+  assert_line "]"
+}
+
+@test "lists the same variables as ENVIRONMENT_VARIABLES.md" {
+  documented="$(sed -n 's/^`\(GO\(ENV\|_BUILD\)_[A-Z0-9_]*\)`.*/\1/p' "${BATS_TEST_DIRNAME}/../ENVIRONMENT_VARIABLES.md")"
+
+  run goenv-env-vars
+  assert_success
+  assert_equal "$documented" "$(echo "$output" | grep -E '^GO(ENV|_BUILD)_' | cut -d' ' -f1)"
+}
+
+@test "lists each variable that goenv and its plugins read" {
+  # NOTE: Variables goenv passes on to its own commands, hooks and the
+  # shell, which don't configure it.
+  exempt=(
+    GOENV_ARGV0 GOENV_BIN_PATH GOENV_COMMAND GOENV_COMMAND_PATH GOENV_FILE_ARG GOENV_GO_MOD_ENABLE
+    GOENV_HASHED_GO GOENV_HOOK GOENV_HOOK_STATUS GOENV_INSTALL_WORKER GOENV_NATIVE_EXT GOENV_PREFIX
+    GOENV_PREFIX_PATH GOENV_PREFIX_PATHS GOENV_PRESERVED_ GOENV_PRESERVED_ENV GOENV_SHIM_CACHE_ENTRY
+    GOENV_SHIM_FINGERPRINT GOENV_VERSIONS GOENV_VERSION_FILE GOENV_VERSION_NAME GOENV_VERSION_NAMES
+    GOENV_VERSION_ORIGIN GO_BUILD_DEFAULT_MIRROR GO_BUILD_INSTALL_PREFIX GO_BUILD_VERSION
+  )
+  registered="$(goenv-env-vars | grep -E '^GO(ENV|_BUILD)_' | cut -d' ' -f1)"
+
+  run bash -c 'grep -ohE "\bGO(ENV|_BUILD)_[A-Z0-9_]+" "$@" | sort -u' - \
+    "${BATS_TEST_DIRNAME}"/../libexec/* "${BATS_TEST_DIRNAME}"/../plugins/*/bin/*
+  assert_success
+  for name in "${lines[@]}"; do
+    [[ " ${exempt[*]} " == *" ${name} "* ]] || grep -qxF "$name" <<<"$registered" ||
+      flunk "${name} is read by goenv but not in the registry of goenv-env-vars"
+  done
+}
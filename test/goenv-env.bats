@@ -0,0 +1,88 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  create_executable "1.22.0" "go" <<SH
+#!/bin/sh
+SH
+  mkdir -p "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+  export GOENV_VERSION=1.22.0
+  unset GOROOT GOPATH GOCACHE GOTOOLCHAIN
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage env
+  assert_success_out <<OUT
+Usage: goenv env [--shell bash|zsh|sh|fish|pwsh|json]
+OUT
+}
+
+@test "has completion support" {
+  run goenv-env --complete --shell
+  assert_success_out <<OUT
+bash
+fish
+json
+pwsh
+sh
+zsh
+OUT
+}
+
+@test "fails and prints usage for unknown arguments" {
+  run goenv-env --json
+  assert_failure "Usage: goenv env [--shell bash|zsh|sh|fish|pwsh|json]"
+}
+
+@test "fails for an unknown shell" {
+  run goenv-env --shell tcsh
+  assert_failure "goenv: unknown shell 'tcsh', expected bash, zsh, sh, fish, pwsh or json"
+}
+
+@test "prints the variables goenv exec sets, with advice on GOTOOLCHAIN" {
+  GOENV_GOCACHE_DIR="${GOENV_TEST_DIR}/cache" run goenv-env
+  assert_success
+  assert_line "export GOENV_VERSION='1.22.0'"
+  assert_line "export GOROOT='${GOENV_ROOT}/versions/1.22.0'"
+  assert_line "export GOPATH='${HOME}/go/1.22.0'"
+  assert_line "export GOCACHE='${GOENV_TEST_DIR}/cache/1.22.0-$(goenv-cache --platform)'"
+  assert_line "# GOTOOLCHAIN is not set, so go may run the toolchain a go.mod asks for instead of go 1.22.0."
+  [[ "$output" == *"export PATH='${GOENV_ROOT}/versions/1.22.0/bin:"* ]]
+  [[ "$output" != *":${BATS_TEST_DIRNAME}/../libexec:"* ]]
+}
+
+@test "leaves out the advice when GOTOOLCHAIN is local" {
+  GOTOOLCHAIN=local run goenv-env
+  assert_success
+  refute_line "# Set GOTOOLCHAIN=local to always run go 1.22.0."
+}
+
+@test "prints the variables for fish, PowerShell and as JSON" {
+  GOTOOLCHAIN=local run goenv-env --shell fish
+  assert_success
+  assert_line "set -gx GOROOT '${GOENV_ROOT}/versions/1.22.0'"
+  [[ "$output" == *"set -gx PATH '${GOENV_ROOT}/versions/1.22.0/bin' "* ]]
+
+  GOTOOLCHAIN=local run goenv-env --shell pwsh
+  assert_success
+  assert_line "\$env:GOROOT = '${GOENV_ROOT}/versions/1.22.0'"
+
+  GOTOOLCHAIN=local run goenv-env --shell json
+  assert_success
+  assert_line 0 "{"
+  assert_line 1 "  \"GOENV_VERSION\": \"1.22.0\","
+  assert_line "}"
+}
+
+@test "quotes values for the shell" {
+  GOTOOLCHAIN=local GOENV_GOPATH_PREFIX="${GOENV_TEST_DIR}/it's" run goenv-env
+  assert_success
+  assert_line "export GOPATH='${GOENV_TEST_DIR}/it'\\''s/1.22.0'"
+}
+
+@test "fails when the selected version is not installed" {
+  GOENV_VERSION=1.2.3 run goenv-env
+  assert_failure
+}
@@ -9,12 +9,12 @@ setup() {
 
 @test "has usage instructions" {
   run goenv-help --usage exec
-  assert_success "Usage: goenv exec <command> [arg1 arg2...]"
+  assert_success "Usage: goenv exec [--system | -v <version>] [--raw-goroot] [--timeout <duration>] [--env | --project-env] <command> [arg1 arg2...]"
 }
 
 @test "fails with usage instructions when no command is specified" {
   run goenv-exec
-  assert_failure "Usage: goenv exec <command> [arg1 arg2...]"
+  assert_failure "Usage: goenv exec [--system | -v <version>] [--raw-goroot] [--timeout <duration>] [--env | --project-env] <command> [arg1 arg2...]"
 }
 
 @test "fails with version that's not installed but specified by GOENV_VERSION" {
@@ -59,10 +59,42 @@ setup() {
   GOENV_VERSION=1.6.1 run goenv-completions exec
   assert_success_out <<OUT
 --help
+--system
+--version
+--raw-goroot
+--timeout
+--env
+--project-env
 Zgo123unique
 OUT
 }
 
+@test "runs the command with the version given with '-v' instead of the selected one" {
+  mkdir -p "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+  echo 1.6.1 > .go-version
+  create_executable "1.6.1" "Zgo123unique" <<SH
+#!/bin/sh
+echo "1.6.1 \$GOENV_VERSION"
+SH
+  create_executable "1.21.5" "Zgo123unique" <<SH
+#!/bin/sh
+echo "1.21.5 \$GOENV_VERSION"
+SH
+
+  run goenv-exec -v 1.21.5 Zgo123unique
+  assert_success "1.21.5 1.21.5"
+
+  GOENV_VERSION=1.6.1 run goenv-exec --version 1.21.5 Zgo123unique
+  assert_success "1.21.5 1.21.5"
+  assert_equal "1.6.1" "$(cat .go-version)"
+}
+
+@test "fails with a version given with '-v' that's not installed" {
+  run goenv-exec -v 1.99.0 go version
+  assert_failure "goenv: version '1.99.0' is not installed, install it with 'goenv install 1.99.0'"
+}
+
 @test "carries original IFS within hooks for version that's specified by GOENV_VERSION environment variable" {
   create_hook exec hello.bash <<SH
 hellos=(\$(printf "hello\\tugly world\\nagain"))
@@ -350,3 +382,566 @@ $GOENV_ROOT/versions/1.12.0
 /tmp/goenv/example/1.12.0
 OUT
 }
+
+@test "fails with install instructions when 'CGO_ENABLED=1' and no C compiler is found for 'go build'" {
+  create_executable "1.6.1" "go" "#!/bin/sh"
+
+  GOENV_VERSION=1.6.1 CGO_ENABLED=1 CC=does-not-exist-cc run goenv-exec go build
+
+  assert_failure
+  assert_line 0 "goenv: cgo is enabled but the C compiler 'does-not-exist-cc' was not found"
+}
+
+@test "does not check for a C compiler when 'GOENV_DISABLE_CGO_CHECK=1'" {
+  create_executable "1.6.1" "go" "#!/bin/sh"
+
+  GOENV_VERSION=1.6.1 GOENV_DISABLE_CGO_CHECK=1 CGO_ENABLED=1 CC=does-not-exist-cc run goenv-exec go build
+
+  assert_success ""
+}
+
+@test "warns but runs 'go build' when a package imports \"C\" and no C compiler is found" {
+  create_executable "1.6.1" "go" <<SH
+#!$BASH
+echo built
+SH
+  mkdir -p "$GOENV_TEST_DIR/project"
+  cd "$GOENV_TEST_DIR/project"
+  printf 'package main\n\nimport "C"\n' > main.go
+
+  GOENV_VERSION=1.6.1 CC=does-not-exist-cc run goenv-exec go build
+
+  assert_success
+  assert_line 0 "goenv: cgo is enabled but the C compiler 'does-not-exist-cc' was not found"
+  assert_line "built"
+}
+
+@test "checks for a C compiler with the CGO_ENABLED of the project's .goenv.toml" {
+  create_executable "1.6.1" "go" <<SH
+#!$BASH
+echo built
+SH
+  mkdir -p "$GOENV_TEST_DIR/project"
+  cd "$GOENV_TEST_DIR/project"
+  printf 'package main\n\nimport "C"\n' > main.go
+  unset CGO_ENABLED
+
+  printf '[env]\nCGO_ENABLED = "1"\n' > .goenv.toml
+  GOENV_VERSION=1.6.1 CC=does-not-exist-cc run goenv-exec go build
+  assert_failure
+  assert_line 0 "goenv: cgo is enabled but the C compiler 'does-not-exist-cc' was not found"
+
+  printf '[env]\nCGO_ENABLED = "0"\n' > .goenv.toml
+  GOENV_VERSION=1.6.1 CC=does-not-exist-cc run goenv-exec go build
+  assert_success "built"
+}
+
+@test "caches the selected version in 'GOENV_FALLBACK_DIR'" {
+  export GOENV_VERSION="1.6.1"
+  create_executable "1.6.1" "Zgo123unique" "#!/bin/sh"
+  export GOENV_FALLBACK_DIR="${GOENV_TEST_DIR}/fallback"
+
+  run goenv-exec Zgo123unique
+  assert_success ""
+
+  for i in 1 2 3 4 5 6 7 8 9 10; do
+    [ ! -f "${GOENV_FALLBACK_DIR}/.goenv-version" ] || break
+    sleep 0.2
+  done
+  assert [ -x "${GOENV_FALLBACK_DIR}/bin/Zgo123unique" ]
+  assert_equal "1.6.1" "$(cat "${GOENV_FALLBACK_DIR}/.goenv-version")"
+}
+
+@test "exports a per-version 'GOCACHE' when 'GOENV_GOCACHE_DIR' is set" {
+  export GOENV_VERSION="1.6.1"
+  create_executable "1.6.1" "Zgo123unique" <<SH
+#!/bin/sh
+echo "\$GOCACHE"
+SH
+  export GOENV_GOCACHE_DIR="${GOENV_TEST_DIR}/gocache/"
+
+  run goenv-exec Zgo123unique
+  assert_success "${GOENV_TEST_DIR}/gocache/1.6.1-$(goenv-cache --platform)"
+}
+
+@test "keeps 'GOCACHE' in 'GOENV_LOCAL_DIR' when it is set" {
+  export GOENV_VERSION="1.6.1"
+  create_executable "1.6.1" "Zgo123unique" <<SH
+#!/bin/sh
+echo "\$GOCACHE"
+SH
+  export GOENV_LOCAL_DIR="${GOENV_TEST_DIR}/local"
+
+  run goenv-exec Zgo123unique
+  assert_success "${GOENV_TEST_DIR}/local/go-build"
+
+  GOENV_GOCACHE_DIR="${GOENV_ROOT}/gocache" run goenv-exec Zgo123unique
+  assert_success "${GOENV_TEST_DIR}/local/go-build/1.6.1-$(goenv-cache --platform)"
+
+  GOCACHE="${GOENV_TEST_DIR}/elsewhere" run goenv-exec Zgo123unique
+  assert_success "${GOENV_TEST_DIR}/elsewhere"
+}
+
+@test "runs executable exec hooks before the command" {
+  export GOENV_VERSION="1.6.1"
+  create_executable "1.6.1" "Zgo123unique" <<SH
+#!/bin/sh
+echo "ran"
+SH
+  create_executable "${GOENV_ROOT}/hooks/exec" "audit" <<SH
+#!$BASH
+echo "\$GOENV_COMMAND \$GOENV_COMMAND_PATH \$GOENV_PREFIX"
+SH
+
+  run goenv-exec Zgo123unique
+  assert_success_out <<OUT
+Zgo123unique ${GOENV_ROOT}/versions/1.6.1/bin/Zgo123unique ${GOENV_ROOT}/versions/1.6.1
+ran
+OUT
+}
+
+@test "hands the command the locale goenv was run with" {
+  export GOENV_VERSION="1.6.1"
+  create_executable "1.6.1" "Zgo123unique" <<SH
+#!/bin/sh
+echo "LC_ALL=\${LC_ALL-unset}"
+SH
+
+  LC_ALL=C.UTF-8 run goenv exec Zgo123unique
+  assert_success "LC_ALL=C.UTF-8"
+
+  run env -u LC_ALL goenv exec Zgo123unique
+  assert_success "LC_ALL=unset"
+}
+
+@test "hands the command the variables of 'GOENV_PRESERVE_ENV' unchanged" {
+  export GOENV_VERSION="1.6.1"
+  create_executable "1.6.1" "Zgo123unique" <<SH
+#!/bin/sh
+echo "GOFLAGS=\${GOFLAGS-unset} GOPATH=\$GOPATH"
+SH
+  mkdir -p "${GOENV_TEST_DIR}/hooks/exec"
+  cat > "${GOENV_TEST_DIR}/hooks/exec/flags.bash" <<'SH'
+export GOFLAGS=-mod=mod
+SH
+
+  unset GOFLAGS
+  GOENV_HOOK_PATH="${GOENV_TEST_DIR}/hooks" GOPATH=/keep/me GOENV_PRESERVE_ENV=GOFLAGS,GOPATH run goenv exec Zgo123unique
+  assert_success "GOFLAGS=unset GOPATH=/keep/me"
+}
+
+@test "hands the command the variables of 'env_preserve' in .goenv.toml unchanged" {
+  export GOENV_VERSION="1.6.1"
+  create_executable "1.6.1" "Zgo123unique" <<SH
+#!/bin/sh
+echo "GOFLAGS=\${GOFLAGS-unset} LANG=\$LANG"
+SH
+  mkdir -p "${GOENV_TEST_DIR}/hooks/exec"
+  cat > "${GOENV_TEST_DIR}/hooks/exec/env.bash" <<'SH'
+export GOFLAGS=-mod=mod LANG=C
+SH
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cat > "${GOENV_TEST_DIR}/project/.goenv.toml" <<TOML
+env_preserve = ["GOFLAGS", 'LANG']  # for the IDE
+
+[env]
+GOFLAGS = "-race"
+TOML
+  cd "${GOENV_TEST_DIR}/project"
+
+  unset GOFLAGS
+  GOENV_HOOK_PATH="${GOENV_TEST_DIR}/hooks" LANG=de_DE.UTF-8 run goenv exec Zgo123unique
+  assert_success "GOFLAGS=unset LANG=de_DE.UTF-8"
+}
+
+@test "runs the system version of the command with --system" {
+  export GOENV_VERSION="1.6.1"
+  create_executable "1.6.1" "Zgo123unique" <<SH
+#!/bin/sh
+echo goenv go
+SH
+  create_executable "${GOENV_TEST_DIR}/bin" "Zgo123unique" <<SH
+#!/bin/sh
+echo system go
+SH
+
+  run goenv-exec --system Zgo123unique
+  assert_success "system go"
+}
+
+@test "appends 'GOENV_GOPATH_EXTRA' entries after the managed 'GOPATH', without duplicates" {
+  create_version "1.12.0"
+  create_executable "1.12.0" "go-paths" <<SH
+#!$BASH
+echo \$GOPATH
+SH
+
+  GOPATH=/shared/modules GOENV_APPEND_GOPATH=1 GOENV_VERSION=1.12.0 GOENV_GOPATH_EXTRA="/corp/go:/shared/modules/:/corp/go" run goenv-exec go-paths
+
+  assert_success "$HOME/go/1.12.0:/shared/modules:/corp/go"
+}
+
+@test "ignores relative 'GOENV_GOPATH_EXTRA' entries with a warning" {
+  create_version "1.12.0"
+  create_executable "1.12.0" "go-paths" <<SH
+#!$BASH
+echo \$GOPATH
+SH
+
+  GOENV_VERSION=1.12.0 GOENV_GOPATH_EXTRA="corp/go:/corp/go" run goenv-exec go-paths
+
+  assert_success_out <<OUT
+goenv: ignoring 'corp/go' in GOENV_GOPATH_EXTRA, GOPATH entries must be absolute paths
+$HOME/go/1.12.0:/corp/go
+OUT
+}
+
+@test "does not append 'GOENV_GOPATH_EXTRA' entries when 'GOENV_DISABLE_GOPATH' is 1" {
+  create_version "1.12.0"
+  create_executable "1.12.0" "go-paths" <<SH
+#!$BASH
+echo \$GOPATH
+SH
+
+  GOPATH=/mine GOENV_DISABLE_GOPATH=1 GOENV_VERSION=1.12.0 GOENV_GOPATH_EXTRA="/corp/go" run goenv-exec go-paths
+
+  assert_success "/mine"
+}
+
+@test "runs the command with its name as argv[0]" {
+  mkdir -p "${GOENV_ROOT}/versions/1.10.1/bin"
+  ln -s "$(command -v bash)" "${GOENV_ROOT}/versions/1.10.1/bin/gotool"
+
+  GOENV_VERSION=1.10.1 run goenv-exec gotool -c 'echo $0'
+  assert_success "gotool"
+}
+
+@test "runs a shim invoked through a symlink with the name of the symlink as argv[0]" {
+  mkdir -p "${GOENV_ROOT}/versions/1.10.1/bin" "${GOENV_TEST_DIR}/bin"
+  ln -s "$(command -v bash)" "${GOENV_ROOT}/versions/1.10.1/bin/gotool"
+  goenv-rehash
+  ln -s "${GOENV_ROOT}/shims/gotool" "${GOENV_TEST_DIR}/bin/othertool"
+  ln -s othertool "${GOENV_TEST_DIR}/bin/thirdtool"
+
+  GOENV_VERSION=1.10.1 run othertool -c 'echo $0 ${GOENV_ARGV0:-unset}'
+  assert_success "othertool unset"
+
+  GOENV_VERSION=1.10.1 run thirdtool -c 'echo $0'
+  assert_success "thirdtool"
+}
+
+@test "annotates 'go env' with the values goenv overrode on stderr when 'GOENV_ANNOTATE' is 1" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!/bin/sh
+echo "GOROOT='\$GOROOT'"
+SH
+
+  GOENV_ANNOTATE=1 run goenv-exec go env
+  assert_success_out <<OUT
+GOROOT='${GOENV_ROOT}/versions/1.10.1'
+goenv: go 1.10.1 (set by GOENV_VERSION environment variable)
+goenv: GOROOT=${GOENV_ROOT}/versions/1.10.1 is the prefix of go 1.10.1 (disable with GOENV_DISABLE_GOROOT=1)
+goenv: GOPATH=${HOME}/go/1.10.1 is the GOPATH of go 1.10.1 (disable with GOENV_DISABLE_GOPATH=1)
+goenv: PATH starts with ${GOENV_ROOT}/versions/1.10.1/bin
+OUT
+
+  GOENV_ANNOTATE=1 run bash -c "goenv-exec go env 2>/dev/null"
+  assert_success "GOROOT='${GOENV_ROOT}/versions/1.10.1'"
+}
+
+@test "does not annotate 'go env' unless 'GOENV_ANNOTATE' is 1" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!/bin/sh
+echo "GOROOT='\$GOROOT'"
+SH
+
+  run goenv-exec go env
+  assert_success "GOROOT='${GOENV_ROOT}/versions/1.10.1'"
+}
+
+@test "sets organization defaults that aren't set already" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!/bin/sh
+echo "\$GOFLAGS \$GONOSUMDB"
+SH
+  mkdir -p "$GOENV_TEST_DIR"
+  cat > "${GOENV_TEST_DIR}/defaults.env" <<ENV
+GOFLAGS=-trimpath
+GONOSUMDB=*.corp.example.com
+ENV
+  export GOENV_ORG_DEFAULTS="${GOENV_TEST_DIR}/defaults.env"
+  unset GOFLAGS GONOSUMDB
+
+  run goenv-exec go env
+  assert_success "-trimpath *.corp.example.com"
+
+  GOFLAGS=-mod=mod run goenv-exec go env
+  assert_success "-mod=mod *.corp.example.com"
+}
+
+@test "sets the variables of the project's .goenv.toml unless they are set" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!/bin/sh
+echo "\$GOFLAGS \$GOPRIVATE \$GONOSUMDB"
+SH
+  mkdir -p "${GOENV_TEST_DIR}/project/sub"
+  cat > "${GOENV_TEST_DIR}/project/.goenv.toml" <<TOML
+# Settings for the corp modules
+[tools]
+GOFLAGS = "-race"
+
+[env]
+GOFLAGS = "-mod=mod"  # vendored elsewhere
+GOPRIVATE = 'git.example.com/*'
+GONOSUMDB = git.example.com
+TOML
+  cd "${GOENV_TEST_DIR}/project/sub"
+  unset GOFLAGS GOPRIVATE GONOSUMDB
+
+  run goenv-exec go env
+  assert_success "-mod=mod git.example.com/* git.example.com"
+
+  GOPRIVATE=github.com/me run goenv-exec go env
+  assert_success "-mod=mod github.com/me git.example.com"
+}
+
+@test "ignores variables other than GO* in .goenv.toml" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!/bin/sh
+echo "\$GOPROXY \$EDITOR"
+SH
+  mkdir -p "$GOENV_TEST_DIR"
+  cat > "${GOENV_TEST_DIR}/.goenv.toml" <<TOML
+[env]
+EDITOR = "vi"
+GOPROXY = "https://proxy.example.com"
+TOML
+  cd "$GOENV_TEST_DIR"
+  unset GOPROXY EDITOR
+
+  run goenv-exec go env
+  assert_success
+  assert_line 0 "goenv: ignoring EDITOR in ${GOENV_TEST_DIR}/.goenv.toml, only GO* and CGO_* variables are set"
+  assert_line 1 "https://proxy.example.com "
+}
+
+@test "prints the variables of .goenv.toml with '--project-env'" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" "#!/bin/sh"
+  mkdir -p "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+  unset GOFLAGS GOPRIVATE
+
+  run goenv-exec --project-env
+  assert_failure "goenv: no .goenv.toml in '${GOENV_TEST_DIR}' or above"
+
+  cat > .goenv.toml <<TOML
+[env]
+GOFLAGS = "-mod=mod"
+GOPRIVATE = "git.example.com/*"
+TOML
+
+  GOPRIVATE=github.com/me run goenv-exec --project-env
+  assert_success_out <<OUT
+GOFLAGS=-mod=mod
+GOPRIVATE=github.com/me  # set in the environment already
+OUT
+}
+
+@test "sets the variables of the version's env file after the user's and the project's" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!/bin/sh
+echo "\$GODEBUG \$CGO_ENABLED \$GOFLAGS \$GOAMD64"
+SH
+  cat > "${GOENV_ROOT}/versions/1.10.1/env" <<ENV
+# Only for 1.10.1
+GODEBUG=httpmuxgo121=1,tlsrsakex=1
+export CGO_ENABLED=0
+GOFLAGS="-race"
+GOAMD64='v3'
+ENV
+  mkdir -p "$GOENV_TEST_DIR"
+  cat > "${GOENV_TEST_DIR}/.goenv.toml" <<TOML
+[env]
+GOFLAGS = "-mod=mod"
+TOML
+  cd "$GOENV_TEST_DIR"
+  unset GODEBUG CGO_ENABLED GOFLAGS GOAMD64
+
+  run goenv-exec go env
+  assert_success "httpmuxgo121=1,tlsrsakex=1 0 -mod=mod v3"
+
+  CGO_ENABLED=1 run goenv-exec go env
+  assert_success "httpmuxgo121=1,tlsrsakex=1 1 -mod=mod v3"
+}
+
+@test "ignores PATH, GOENV_* and malformed lines in the version's env file" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!/bin/sh
+echo "\$GOENV_DEBUG \$GOTOOLCHAIN"
+SH
+  cat > "${GOENV_ROOT}/versions/1.10.1/env" <<ENV
+GOENV_DEBUG=1
+GOTOOLCHAIN local
+GOTOOLCHAIN=local
+ENV
+  unset GOENV_DEBUG GOTOOLCHAIN
+
+  run goenv-exec go env
+
+  assert_success
+  assert_line 0 "goenv: ignoring GOENV_DEBUG in ${GOENV_ROOT}/versions/1.10.1/env, PATH and GOENV_* variables are set by goenv"
+  assert_line 1 "goenv: ignoring \"GOTOOLCHAIN local\" in ${GOENV_ROOT}/versions/1.10.1/env, expected NAME=value"
+  assert_line 2 " local"
+}
+
+@test "stops a command that runs longer than the timeout with status 124" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!$BASH
+sleep 30 &
+wait
+echo "finished"
+SH
+
+  SECONDS=0
+  run goenv-exec --timeout 1s go test
+  assert_failure "goenv: 'go' timed out after 1s"
+  [ "$status" -eq 124 ]
+  [ "$SECONDS" -lt 10 ]
+}
+
+@test "keeps the status of a command that finishes within the timeout" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!/bin/sh
+echo "done"
+exit 3
+SH
+
+  GOENV_EXEC_TIMEOUT=1m run goenv-exec go test
+  assert_failure "done"
+  [ "$status" -eq 3 ]
+}
+
+@test "passes signals on to a command run with a timeout and dies of the same" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!$BASH
+trap 'echo "interrupted"; trap - INT; kill -INT \$\$' INT
+echo "started"
+sleep 30 &
+wait
+SH
+  mkdir -p "$GOENV_TEST_DIR"
+
+  set -m
+  goenv-exec --timeout 1m go test > "${GOENV_TEST_DIR}/output" &
+  pid="$!"
+  set +m
+  for i in $(seq 50); do
+    ! grep -q "started" "${GOENV_TEST_DIR}/output" || break
+    sleep 0.1
+  done
+  status=0
+  kill -INT "$pid"
+  wait "$pid" || status="$?"
+
+  assert_equal "130" "$status"
+  assert_equal "$(printf "started\ninterrupted")" "$(cat "${GOENV_TEST_DIR}/output")"
+}
+
+@test "fails with an invalid timeout" {
+  GOENV_VERSION=1.10.1 run goenv-exec --timeout 5d go version
+  assert_failure "goenv: invalid timeout '5d', expected seconds or a duration like '90s', '15m' or '1h'"
+}
+
+@test "runs 'go generate' with a scoped PATH in projects that opted in" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!/bin/sh
+echo "\$PATH"
+SH
+  mkdir -p "${GOENV_TEST_DIR}/app"
+  cd "${GOENV_TEST_DIR}/app"
+  echo "/opt/tools" > .goenv-generate
+
+  run goenv-exec go generate
+  assert_success
+  assert_output "${GOENV_ROOT}/versions/1.10.1/bin:${HOME}/go/1.10.1/bin:/opt/tools:${GOENV_ROOT}/generate/$(echo "${PWD}/.goenv-generate" | cksum | cut -d' ' -f1)"
+}
+
+@test "runs the real binaries without GOROOT or shims in the way with '--raw-goroot'" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!/bin/sh
+echo "GOROOT=\$GOROOT"
+echo "GOROOT_BOOTSTRAP=\$GOROOT_BOOTSTRAP"
+echo "PATH=\$PATH"
+echo "GOENV_RAW_GOROOT=\$GOENV_RAW_GOROOT"
+SH
+  unset GOROOT GOROOT_BOOTSTRAP
+
+  PATH="${GOENV_ROOT}/shims:${BATS_TEST_DIRNAME}/../libexec:/usr/bin:/bin" run goenv-exec --raw-goroot go version
+  assert_success_out <<OUT
+GOROOT=
+GOROOT_BOOTSTRAP=${GOENV_ROOT}/versions/1.10.1
+PATH=${GOENV_ROOT}/versions/1.10.1/bin:${BATS_TEST_DIRNAME}/../libexec:/usr/bin:/bin
+GOENV_RAW_GOROOT=1
+OUT
+}
+
+@test "runs raw inside a Go source checkout unless 'GOENV_RAW_GOROOT' is 0" {
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!/bin/sh
+echo "GOROOT=\$GOROOT"
+SH
+  mkdir -p "${GOENV_TEST_DIR}/goroot/src/cmd/dist"
+  touch "${GOENV_TEST_DIR}/goroot/src/make.bash"
+  cd "${GOENV_TEST_DIR}/goroot/src"
+  unset GOROOT
+
+  run goenv-exec go tool dist env
+  assert_success "GOROOT="
+
+  GOENV_RAW_GOROOT=0 run goenv-exec go tool dist env
+  assert_success "GOROOT=${GOENV_ROOT}/versions/1.10.1"
+}
+
+@test "keeps Go caches out of a GOENV_ROOT owned by another user in a container" {
+  if [ "$(id -u)" != "0" ]; then
+    skip "needs root to create files owned by another user"
+  fi
+  export GOENV_VERSION="1.10.1"
+  create_executable "1.10.1" "go" <<SH
+#!/bin/sh
+echo "\$GOCACHE"
+echo "\$GOMODCACHE"
+SH
+  chown nobody "$GOENV_ROOT"
+  export container=docker
+  export GOENV_GOCACHE_DIR="${GOENV_ROOT}/cache"
+  export GOENV_GOPATH_PREFIX="${GOENV_ROOT}/gopath"
+  export GOENV_UID_CACHE_DIR="${GOENV_TEST_DIR}/uid-cache"
+  unset GOCACHE GOMODCACHE
+
+  run goenv-exec go env
+  assert_success_out <<OUT
+${GOENV_TEST_DIR}/uid-cache/build/1.10.1-$(goenv-cache --platform)
+${GOENV_TEST_DIR}/uid-cache/mod
+OUT
+
+  GOENV_UID_REDIRECT=0 run goenv-exec go env
+  assert_success_out <<OUT
+${GOENV_ROOT}/cache/1.10.1-$(goenv-cache --platform)
+
+OUT
+}
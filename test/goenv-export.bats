@@ -0,0 +1,102 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "${GOENV_TEST_DIR}/definitions"
+  cat > "${GOENV_TEST_DIR}/definitions/1.22.4" <<DEF
+install_darwin_arm "Go Darwin arm 1.22.4" "go1.22.4.darwin-arm64.tar.gz#242b78dc4c8f3d5435d28a0d2cec9b4c1aa999b601fb8aa59fb4e5a1364bf827"
+
+install_bsd_64bit "Go Freebsd 64bit 1.22.4" "go1.22.4.freebsd-amd64.tar.gz#88d44500e1701dd35797619774d6dd51bf60f45a8338b0a82ddc018e4e63fb78"
+
+install_linux_64bit "Go Linux 64bit 1.22.4" "go1.22.4.linux-amd64.tar.gz#ba79d4526102575196273416239cca418a651e049c2b099f3159db85e7bade7d"
+DEF
+  export GO_BUILD_DEFINITIONS="${GOENV_TEST_DIR}/definitions"
+  create_version "1.22.4"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage export
+  assert_success_out <<OUT
+Usage: goenv export nix [--home-manager] [<version>]
+       goenv export --github-env [<version>]
+       goenv export --dotenv [<version>]
+OUT
+}
+
+@test "prints a Nix expression pinning the selected version by checksum" {
+  GOENV_VERSION=1.22.4 run goenv-export nix
+  assert_success
+  assert_line 0 "# Go 1.22.4, exported by 'goenv export nix' from the go-build definition."
+  assert_line 1 "{ pkgs ? import <nixpkgs> { } }:"
+  assert_line '    aarch64-darwin = { url = "https://go.dev/dl/go1.22.4.darwin-arm64.tar.gz"; sha256 = "242b78dc4c8f3d5435d28a0d2cec9b4c1aa999b601fb8aa59fb4e5a1364bf827"; };'
+  assert_line '    x86_64-linux = { url = "https://go.dev/dl/go1.22.4.linux-amd64.tar.gz"; sha256 = "ba79d4526102575196273416239cca418a651e049c2b099f3159db85e7bade7d"; };'
+  assert_line '  version = "1.22.4";'
+  [[ "$output" != *freebsd* ]]
+}
+
+@test "prints a Home Manager module" {
+  run goenv-export nix --home-manager 1.22.4
+  assert_success
+  assert_line 1 "{ pkgs, ... }:"
+  assert_line "  home.packages = [ go ];"
+  assert_line '  home.sessionVariables.GOROOT = "${go}/share/go";'
+}
+
+@test "fails for a version without a go-build definition" {
+  GOENV_VERSION=system run goenv-export nix
+  assert_failure "goenv: the system version can't be exported, select an installed version"
+
+  create_version "1.21.0"
+  run goenv-export nix 1.21.0
+  assert_failure "goenv: no go-build definition found for 1.21.0, so its checksums are unknown"
+}
+
+@test "adds the selected version to the environment of the following GitHub Actions steps" {
+  export GITHUB_ENV="${GOENV_TEST_DIR}/github_env" GITHUB_PATH="${GOENV_TEST_DIR}/github_path"
+  echo "FOO=bar" > "$GITHUB_ENV"
+
+  GOENV_VERSION=1.22.4 run goenv-export --github-env
+  assert_success "goenv: the following steps use Go 1.22.4 from ${GOENV_ROOT}/versions/1.22.4"
+  run cat "$GITHUB_ENV"
+  assert_success_out <<OUT
+FOO=bar
+GOENV_VERSION=1.22.4
+GOROOT=${GOENV_ROOT}/versions/1.22.4
+GOPATH=${HOME}/go/1.22.4
+OUT
+  run cat "$GITHUB_PATH"
+  assert_success_out <<OUT
+${HOME}/go/1.22.4/bin
+${GOENV_ROOT}/versions/1.22.4/bin
+OUT
+}
+
+@test "fails with --github-env outside of GitHub Actions" {
+  unset GITHUB_ENV GITHUB_PATH
+
+  run goenv-export --github-env 1.22.4
+  assert_failure "goenv: GITHUB_ENV and GITHUB_PATH aren't set, --github-env only works in GitHub Actions"
+}
+
+@test "fails with --github-env when the version isn't installed" {
+  export GITHUB_ENV="${GOENV_TEST_DIR}/github_env" GITHUB_PATH="${GOENV_TEST_DIR}/github_path"
+
+  run goenv-export --github-env 1.21.0
+  assert_failure
+  assert [ ! -e "$GITHUB_ENV" ]
+}
+
+@test "prints the selected version as a dotenv file" {
+  GOENV_DISABLE_GOPATH=1 run goenv-export --dotenv 1.22.4
+  assert_success_out <<OUT
+GOENV_VERSION=1.22.4
+GOROOT=${GOENV_ROOT}/versions/1.22.4
+OUT
+}
+
+@test "fails and prints usage with --home-manager for a dotenv file" {
+  run goenv-export --dotenv --home-manager
+  assert_failure
+  assert_line 0 "Usage: goenv export nix [--home-manager] [<version>]"
+}
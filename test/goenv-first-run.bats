@@ -0,0 +1,80 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "$GOENV_TEST_DIR" "$HOME"
+  cd "$GOENV_TEST_DIR"
+  export GOENV_SHELL=zsh
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv-install" <<SH
+#!/bin/sh
+echo "installed \$1"
+mkdir -p "${GOENV_ROOT}/versions/1.24.0/bin"
+SH
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage first-run
+  assert_success "Usage: goenv first-run"
+}
+
+@test "loads goenv in the shell's startup file and installs the latest Go" {
+  cat > answers.yaml <<YAML
+init.setup: y
+first-run.install: y
+YAML
+
+  GOENV_ANSWERS=answers.yaml run goenv-first-run
+  assert_success_out <<OUT
+Welcome to goenv! Let's set it up (skip this with 'goenv --no-interactive').
+
+1. Your shell is zsh.
+zsh: Load goenv in ${HOME}/.zshrc? (y/N) y
+zsh: added goenv to ${HOME}/.zshrc, open a new shell to use it
+2. No Go version is installed yet.
+   Install the latest stable Go and use it by default? (y/N) y
+installed latest
+3. To check the setup, open a new shell and run:
+     goenv version   # prints the selected Go version and what selected it
+     go version      # runs the selected Go version
+     command -v go   # prints ${GOENV_ROOT}/shims/go
+
+OUT
+  assert_equal '# >>> goenv >>>' "$(head -n 1 "${HOME}/.zshrc")"
+  assert_equal 'eval "$(goenv init -)"' "$(tail -n 2 "${HOME}/.zshrc" | head -n 1)"
+  assert_equal "1.24.0" "$(cat "${GOENV_ROOT}/version")"
+  assert [ -e "${GOENV_ROOT}/.first-run" ]
+}
+
+@test "loads goenv in ~/.bashrc on Linux even when there's a ~/.bash_profile" {
+  [ "$(uname -s)" != "Darwin" ] || skip "macOS terminals read ~/.bash_profile"
+  touch "${HOME}/.bash_profile"
+  printf 'init.setup: y\nfirst-run.install: n\n' > answers.yaml
+
+  GOENV_SHELL=bash GOENV_ANSWERS=answers.yaml run goenv-first-run
+  assert_success
+  assert_line "bash: added goenv to ${HOME}/.bashrc, open a new shell to use it"
+  assert [ ! -s "${HOME}/.bash_profile" ]
+}
+
+@test "skips the steps that are declined" {
+  run goenv-first-run < /dev/null
+  assert_success
+  assert_line "zsh: skipped, add this to ${HOME}/.zshrc yourself:"
+  assert_line "   Skipped, install one later with 'goenv install latest'."
+  assert [ ! -e "${HOME}/.zshrc" ]
+  assert [ ! -d "${GOENV_ROOT}/versions/1.24.0" ]
+}
+
+@test "tells how to load goenv in an unknown shell" {
+  GOENV_SHELL=tcsh run goenv-first-run < /dev/null
+  assert_success
+  assert_line "   Load goenv in new shells by adding this to your shell's startup file:"
+  assert_line '     eval "$(goenv init -)"'
+}
+
+@test "does not run by itself without a terminal" {
+  run goenv version-name < /dev/null
+  assert_success "system"
+  assert [ ! -e "${GOENV_ROOT}/.first-run" ]
+}
@@ -0,0 +1,83 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+# Runs a command with a terminal as stdout, so that output is shortened.
+run_on_terminal() {
+  if script -qec true /dev/null >/dev/null 2>&1; then
+    run script -qec "$*" /dev/null
+  else
+    run script -q /dev/null bash -c "$*"
+  fi
+  output="${output//$'\r'/}"
+  lines=()
+  while IFS= read -r line; do lines+=("$line"); done <<< "$output"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage format
+  assert_success_out <<OUT
+Usage: goenv format table [<column>]
+       goenv format fit
+       goenv format wrap <indent>
+OUT
+}
+
+@test "aligns the columns of a table" {
+  run goenv-format table <<IN
+stringer golang.org/x/tools/cmd/stringer installed
+gqlgen github.com/99designs/gqlgen/v2 stale
+IN
+  assert_success_out <<OUT
+stringer golang.org/x/tools/cmd/stringer installed
+gqlgen   github.com/99designs/gqlgen/v2  stale
+OUT
+}
+
+@test "keeps full values when the output isn't a terminal" {
+  COLUMNS=30 run goenv-format table 2 <<IN
+stringer golang.org/x/tools/cmd/stringer installed
+IN
+  assert_success "stringer golang.org/x/tools/cmd/stringer installed"
+}
+
+@test "shortens a column in the middle to fit a terminal" {
+  printf 'stringer golang.org/x/tools/cmd/stringer installed\ngqlgen github.com/99designs/gqlgen/v2 stale\n' > "${BATS_TMPDIR}/table"
+
+  run_on_terminal "COLUMNS=40 goenv-format table 2 < ${BATS_TMPDIR}/table"
+  assert_success_out <<OUT
+stringer golang.or.../stringer installed
+gqlgen   github.co...gqlgen/v2 stale
+OUT
+
+  run_on_terminal "COLUMNS=40 GOENV_NO_TRUNCATE=1 goenv-format table 2 < ${BATS_TMPDIR}/table"
+  assert_success_out <<OUT
+stringer golang.org/x/tools/cmd/stringer installed
+gqlgen   github.com/99designs/gqlgen/v2  stale
+OUT
+}
+
+@test "shortens the longest path of a line to fit a terminal" {
+  echo "* 1.6.1 (set by /home/someone/very/long/path/to/a/project/.go-version)" > "${BATS_TMPDIR}/line"
+
+  run_on_terminal "COLUMNS=50 goenv-format fit < ${BATS_TMPDIR}/line"
+  assert_success "* 1.6.1 (set by /home/someone/v...ect/.go-version)"
+}
+
+@test "wraps long lines with an indent" {
+  COLUMNS=40 run goenv-format wrap 8 <<IN
+[WARN]  tools declared in go.mod are not installed: stringer, run 'goenv tools sync'
+[OK]    short
+IN
+  assert_success_out <<OUT
+[WARN]  tools declared in go.mod are not
+        installed: stringer, run 'goenv
+        tools sync'
+[OK]    short
+OUT
+
+  COLUMNS=40 GOENV_WIDE=1 run goenv-format wrap 8 <<IN
+[WARN]  tools declared in go.mod are not installed: stringer, run 'goenv tools sync'
+IN
+  assert_success "[WARN]  tools declared in go.mod are not installed: stringer, run 'goenv tools sync'"
+}
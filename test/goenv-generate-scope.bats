@@ -0,0 +1,64 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "${GOENV_TEST_DIR}/app" "${GOENV_TEST_DIR}/bin"
+  cd "${GOENV_TEST_DIR}/app"
+  export GOENV_VERSION="1.22.5"
+  export GOROOT="${GOENV_ROOT}/versions/1.22.5"
+  export GOPATH="${HOME}/go/1.22.5"
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+[ "\$1 \$2 \${3##*/}" = "version -m stringer" ] && printf '\tmod\tgolang.org/x/tools\tv0.20.0\n'
+exit 0
+SH
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage generate-scope
+  assert_success "Usage: goenv generate-scope <bin-path>"
+}
+
+@test "fails without printing anything when the project hasn't opted in" {
+  run goenv-generate-scope "${GOROOT}/bin"
+  assert_failure ""
+}
+
+@test "prints a PATH of the Go version, its GOPATH and the allowed entries" {
+  create_executable "${GOENV_TEST_DIR}/bin" "protoc" "#!/bin/sh"
+  printf '# tools\nprotoc\n/opt/tools/\n' > .goenv-generate
+
+  run goenv-generate-scope "${GOROOT}/bin"
+  assert_success "${GOROOT}/bin:${GOPATH}/bin:/opt/tools:${GOENV_ROOT}/generate/$(echo "${PWD}/.goenv-generate" | cksum | cut -d' ' -f1)"
+  assert_equal "${GOENV_TEST_DIR}/bin/protoc" "$(readlink "${output##*:}/protoc")"
+}
+
+@test "records the tools used by go:generate directives in a manifest" {
+  create_executable "${GOPATH}/bin" "stringer" "#!/bin/sh"
+  create_executable "${GOENV_TEST_DIR}/bin" "protoc" "#!/bin/sh"
+  create_executable "${GOENV_TEST_DIR}/bin" "mockgen" "#!/bin/sh"
+  echo "protoc" > .goenv-generate
+  mkdir -p pkg
+  cat > pkg/gen.go <<GO
+package pkg
+
+//go:generate stringer -type=Kind
+//go:generate protoc --go_out=. api.proto
+//go:generate mockgen -source=pkg.go
+//go:generate go run golang.org/x/tools/cmd/goimports@v0.21.0 -w .
+GO
+
+  run goenv-generate-scope "${GOROOT}/bin"
+  assert_success
+
+  run cat .goenv-generate-manifest
+  assert_success_out <<OUT
+# Tools used by go generate, recorded by goenv (see 'goenv help generate-scope')
+go 1.22.5
+golang.org/x/tools/cmd/goimports v0.21.0
+mockgen not-allowed
+protoc sha256:$(printf '#!/bin/sh\n' | sha256sum | cut -d' ' -f1)
+stringer v0.20.0
+OUT
+}
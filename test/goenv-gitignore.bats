@@ -0,0 +1,60 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "${GOENV_TEST_DIR}/app/sub"
+  cd "${GOENV_TEST_DIR}/app"
+  git init -q .
+  root="$(pwd -P)"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage gitignore
+  assert_success "Usage: goenv gitignore <file> [<file>...]"
+}
+
+@test "fails and prints usage without files" {
+  run goenv-gitignore
+  assert_failure "Usage: goenv gitignore <file> [<file>...]"
+}
+
+@test "adds files to a managed block of .gitignore at the root of the work tree" {
+  echo "/vendor" > .gitignore
+  cd sub
+  touch repro.sh other.sh
+
+  run goenv-gitignore repro.sh
+  assert_success "goenv: added '/sub/repro.sh' to ${root}/.gitignore"
+  run goenv-gitignore other.sh repro.sh
+  assert_success "goenv: added '/sub/other.sh' to ${root}/.gitignore"
+
+  run cat ../.gitignore
+  assert_success_out <<OUT
+/vendor
+
+# BEGIN goenv (machine-specific files, managed by goenv)
+/sub/repro.sh
+/sub/other.sh
+# END goenv
+OUT
+}
+
+@test "leaves files alone that are already ignored" {
+  echo "*.sh" > .gitignore
+  touch repro.sh
+
+  run goenv-gitignore repro.sh
+  assert_success ""
+  assert_equal "*.sh" "$(cat .gitignore)"
+}
+
+@test "does nothing outside of a git work tree" {
+  mkdir -p "${GOENV_TEST_DIR}/plain"
+  cd "${GOENV_TEST_DIR}/plain"
+  touch repro.sh
+
+  run goenv-gitignore repro.sh
+  assert_success ""
+  assert [ ! -e .gitignore ]
+}
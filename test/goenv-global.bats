@@ -5,7 +5,8 @@ load test_helper
 @test "has usage instructions" {
   run goenv-help --usage global
   assert_success_out <<OUT
-Usage: goenv global [<version>]
+Usage: goenv global [--yes] [<version>]
+       goenv global --unset
 OUT
 }
 
@@ -14,6 +15,8 @@ OUT
   mkdir -p "${GOENV_ROOT}/versions/1.10.9"
   run goenv-global --complete
   assert_success_out <<OUT
+--unset
+--yes
 latest
 system
 1.10.9
@@ -148,3 +151,72 @@ OUT
   run goenv-global
   assert_success "1.2.5"
 }
+
+@test "asks before switching to a version more than one minor version older" {
+  create_version "1.2.2"
+  create_version "1.22.5"
+  echo "1.22.5" > "${GOENV_ROOT}/version"
+  echo "version.downgrade: n" > "${GOENV_TEST_DIR}/answers.yaml"
+
+  GOENV_ANSWERS="${GOENV_TEST_DIR}/answers.yaml" run goenv-global 1.2.2
+  assert_failure_out <<OUT
+goenv: switch from 1.22.5 to the much older 1.2.2? (y/N) n
+goenv: keeping 1.22.5, use --yes to switch without asking
+OUT
+  assert_equal "1.22.5" "$(cat "${GOENV_ROOT}/version")"
+
+  GOENV_ANSWERS="${GOENV_TEST_DIR}/answers.yaml" run goenv-global --yes 1.2.2
+  assert_success ""
+  assert_equal "1.2.2" "$(cat "${GOENV_ROOT}/version")"
+}
+
+@test "only warns about switching to a much older version without a terminal" {
+  create_version "1.20.1"
+  create_version "1.22.5"
+  echo "1.22.5" > "${GOENV_ROOT}/version"
+
+  run goenv-global 1.20.1 < /dev/null
+  assert_success "goenv: switching from 1.22.5 to the much older 1.20.1"
+  assert_equal "1.20.1" "$(cat "${GOENV_ROOT}/version")"
+}
+
+@test "switches to the previous minor version without asking" {
+  create_version "1.21.9"
+  create_version "1.22.5"
+  echo "1.22.5" > "${GOENV_ROOT}/version"
+  echo "version.downgrade: n" > "${GOENV_TEST_DIR}/answers.yaml"
+
+  GOENV_ANSWERS="${GOENV_TEST_DIR}/answers.yaml" run goenv-global 1.21.9
+  assert_success ""
+  assert_equal "1.21.9" "$(cat "${GOENV_ROOT}/version")"
+}
+
+@test "removes the global version with '--unset' and shows the version used instead" {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5"
+  echo "1.22.5" > "${GOENV_ROOT}/version"
+  echo "1.21.0" > "${GOENV_ROOT}/global"
+
+  run goenv-global --unset
+
+  assert_success "goenv: now using system (set by ${GOENV_ROOT}/version)"
+  assert [ ! -e "${GOENV_ROOT}/version" ]
+  assert [ ! -e "${GOENV_ROOT}/global" ]
+}
+
+@test "shows the local version used after '--unset'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5"
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+  echo "1.22.5" > .go-version
+  echo "1.21.0" > "${GOENV_ROOT}/version"
+
+  run goenv-global --unset
+
+  assert_success "goenv: now using 1.22.5 (set by ${GOENV_TEST_DIR}/project/.go-version)"
+}
+
+@test "fails and prints usage when '--unset' is given with a version" {
+  run goenv-global --unset 1.22.5
+  assert_failure
+  assert_line 0 "Usage: goenv global [--yes] [<version>]"
+}
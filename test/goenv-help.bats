@@ -5,7 +5,8 @@ load test_helper
 @test "has usage instructions" {
   run goenv-help --usage help
   assert_success_out <<OUT
-Usage: goenv help [--usage] COMMAND
+Usage: goenv help [--usage|--examples] COMMAND
+       goenv help --search <term>
 OUT
 }
 
@@ -130,3 +131,101 @@ It can contain multiple lines.
 And paragraphs.
 SH
 }
+
+@test "shows examples after extended help" {
+  mkdir -p "${GOENV_TEST_DIR}/bin"
+  cat > "${GOENV_TEST_DIR}/bin/goenv-hello" <<SH
+#!shebang
+# Usage: goenv hello <world>
+# Summary: Says "hello" to you, from goenv
+# This command is useful for saying hello.
+#
+# Examples:
+#   goenv hello world
+#   goenv hello --loud world
+echo hello
+SH
+
+  run goenv-help hello
+  assert_success_out <<SH
+Usage: goenv hello <world>
+
+This command is useful for saying hello.
+
+Examples:
+  goenv hello world
+  goenv hello --loud world
+
+SH
+}
+
+@test "prints only examples with --examples" {
+  mkdir -p "${GOENV_TEST_DIR}/bin"
+  cat > "${GOENV_TEST_DIR}/bin/goenv-hello" <<SH
+#!shebang
+# Usage: goenv hello <world>
+# Summary: Says "hello" to you, from goenv
+#
+# Examples:
+#   goenv hello world
+echo hello
+SH
+
+  run goenv-help --examples hello
+  assert_success_out <<SH
+goenv hello world
+SH
+}
+
+@test "fails with --examples for a command without examples" {
+  mkdir -p "${GOENV_TEST_DIR}/bin"
+  cat > "${GOENV_TEST_DIR}/bin/goenv-hello" <<SH
+#!shebang
+# Usage: goenv hello <world>
+# Summary: Says "hello" to you, from goenv
+echo hello
+SH
+
+  run goenv-help --examples hello
+  assert_failure "goenv: no examples for \`hello'"
+}
+
+@test "searches command documentation" {
+  mkdir -p "${GOENV_TEST_DIR}/bin"
+  cat > "${GOENV_TEST_DIR}/bin/goenv-hello" <<SH
+#!shebang
+# Usage: goenv hello <world>
+# Summary: Says "hello" to you, from goenv
+# Greets the given planet.
+echo hello
+SH
+
+  run goenv-help --search PLANET
+  assert_success
+  assert_line 0 "Commands matching 'PLANET':"
+  assert_line 1 "   hello       Says \"hello\" to you, from goenv"
+  assert_equal 2 "${#lines[@]}"
+}
+
+@test "fails searching for an unknown term" {
+  run goenv-help --search no-such-term-anywhere
+  assert_failure "goenv: no commands match 'no-such-term-anywhere'"
+}
+
+@test "does not color or page help when not writing to a terminal" {
+  mkdir -p "${GOENV_TEST_DIR}/bin"
+  cat > "${GOENV_TEST_DIR}/bin/goenv-hello" <<SH
+#!shebang
+# Usage: goenv hello <world>
+# Summary: Says "hello" to you, from goenv
+echo hello
+SH
+
+  PAGER=false run goenv-help hello
+  assert_success_out <<SH
+Usage: goenv hello <world>
+
+Says "hello" to you, from goenv
+
+SH
+}
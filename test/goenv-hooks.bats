@@ -16,7 +16,8 @@ OUT
 
 @test "prints usage help when no arguments are given" {
   run goenv-hooks
-  assert_failure "Usage: goenv hooks <command>"
+  assert_failure
+  assert_line 0 "Usage: goenv hooks <command>"
 }
 
 @test "prints list of hooks ending with '.bash', for given command" {
@@ -86,3 +87,34 @@ ${HOME}/hola.bash
 ${GOENV_TEST_DIR}/goenv.d/exec/bright.sh
 OUT
 }
+
+@test "runs the executable hooks of an event in order with '--run'" {
+  create_executable "${GOENV_ROOT}/hooks/install" "20-notify" <<SH
+#!$BASH
+echo "notify \$GOENV_HOOK \$GOENV_VERSION"
+SH
+  create_executable "${GOENV_ROOT}/hooks/install" "10-scan" <<SH
+#!$BASH
+echo "scan \$GOENV_VERSION"
+SH
+  mkdir -p "${GOENV_ROOT}/hooks/install"
+  touch "${GOENV_ROOT}/hooks/install/README"
+
+  GOENV_VERSION=1.22.5 run goenv-hooks --run install
+
+  assert_success_out <<OUT
+scan 1.22.5
+notify install 1.22.5
+OUT
+}
+
+@test "reports failing executable hooks without failing" {
+  create_executable "${GOENV_ROOT}/hooks/rehash" "broken" <<SH
+#!$BASH
+exit 3
+SH
+
+  run goenv-hooks --run rehash
+
+  assert_success "goenv: rehash hook '${GOENV_ROOT}/hooks/rehash/broken' failed with status 3"
+}
@@ -5,7 +5,8 @@ load test_helper
 @test "has usage instructions" {
   run goenv-help --usage init
   assert_success_out <<'OUT'
-Usage: eval "$(goenv init - [--no-rehash] [<shell>])"
+Usage: eval "$(goenv init - [--no-rehash] [--shims-only] [<shell>])"
+       goenv init --setup [--remove] [<shell>...]
 OUT
 }
 
@@ -14,9 +15,16 @@ OUT
   assert_success_out <<OUT
 -
 --no-rehash
+--shims-only
+--setup
+--remove
+ash
 bash
+dash
 fish
 ksh
+pwsh
+sh
 zsh
 OUT
 }
@@ -86,6 +94,17 @@ status --is-interactive; and source (goenv init -|psub)
 OUT
 }
 
+@test "prints usage snippet when no '-' argument is given, but shell given is 'pwsh'" {
+  run goenv-init pwsh
+
+  assert_success_out <<'OUT'
+# Load goenv automatically by appending
+# the following to $PROFILE:
+
+iex ((goenv init - pwsh) -join "`n")
+OUT
+}
+
 @test "prints usage snippet when no '-' argument is given, but shell given is 'ksh'" {
   run goenv-init ksh
 
@@ -97,6 +116,17 @@ eval "$(goenv init -)"
 OUT
 }
 
+@test "prints usage snippet when no '-' argument is given, but shell given is 'dash'" {
+  run goenv-init dash
+
+  assert_success_out <<'OUT'
+# Load goenv automatically by appending
+# the following to ~/.profile:
+
+eval "$(goenv init -)"
+OUT
+}
+
 @test "prints usage snippet when no '-' argument is given, but shell given is none of the well known ones" {
   run goenv-init magicalshell
 
@@ -147,12 +177,16 @@ OUT
   assert_line 11 '    shift'
   assert_line 12 '  fi'
   assert_line 13 '  case "$command" in'
-  assert_line 14 '  rehash|shell)'
+  assert_line 14 '  hook|rehash|shell)'
   assert_line 15 '    eval "$(goenv "sh-$command" "$@")";;'
-  assert_line 16 '  *)'
-  assert_line 17 '    command goenv "$command" "$@";;'
-  assert_line 18 '  esac'
-  assert_line 19 '}'
+  assert_line 16 '  install|uninstall)'
+  assert_line 17 '    command goenv "$command" "$@" && { hash -r 2>/dev/null || true; };;'
+  assert_line 18 '  doctor)'
+  assert_line 19 '    GOENV_HASHED_GO="$(hash -t go 2>/dev/null)" command goenv "$command" "$@";;'
+  assert_line 20 '  *)'
+  assert_line 21 '    command goenv "$command" "$@";;'
+  assert_line 22 '  esac'
+  assert_line 23 '}'
 
   assert_success
 }
@@ -174,12 +208,16 @@ OUT
   assert_line 11 '    shift'
   assert_line 12 '  fi'
   assert_line 13 '  case "$command" in'
-  assert_line 14 '  rehash|shell)'
+  assert_line 14 '  hook|rehash|shell)'
   assert_line 15 '    eval "$(goenv "sh-$command" "$@")";;'
-  assert_line 16 '  *)'
-  assert_line 17 '    command goenv "$command" "$@";;'
-  assert_line 18 '  esac'
-  assert_line 19 '}'
+  assert_line 16 '  install|uninstall)'
+  assert_line 17 '    command goenv "$command" "$@" && { hash -r 2>/dev/null || true; };;'
+  assert_line 18 '  doctor)'
+  assert_line 19 '    GOENV_HASHED_GO="${commands[go]}" command goenv "$command" "$@";;'
+  assert_line 20 '  *)'
+  assert_line 21 '    command goenv "$command" "$@";;'
+  assert_line 22 '  esac'
+  assert_line 23 '}'
 
   assert_success
 }
@@ -189,23 +227,60 @@ OUT
 
   assert_line 0  'set -gx GOENV_SHELL fish'
   assert_line 1  "set -gx GOENV_ROOT $GOENV_ROOT"
-  assert_line 2  'if not contains $GOENV_ROOT/shims $PATH'
-  assert_line 3  '  set -gx PATH $PATH $GOENV_ROOT/shims'
-  assert_line 4  'end'
-  assert_line 5  "source '$BATS_TEST_DIRNAME/../libexec/../completions/goenv.fish'"
-  assert_line 6  'command goenv rehash 2>/dev/null'
-  assert_line 7  'function goenv'
-  assert_line 8  '  set command $argv[1]'
-  assert_line 9  '  set -e argv[1]'
-  assert_line 10 '  switch "$command"'
-  assert_line 11 '  case rehash shell'
-  assert_line 12 '    source (goenv "sh-$command" $argv|psub)'
-  assert_line 13 "  case '*'"
-  assert_line 14 '    command goenv "$command" $argv'
-  assert_line 15 '  end'
-  assert_line 16 'end'
+  assert_line 2  'if functions -q fish_add_path'
+  assert_line 3  '  fish_add_path --append --path $GOENV_ROOT/shims'
+  assert_line 4  'else if not contains $GOENV_ROOT/shims $PATH'
+  assert_line 5  '  set -gx PATH $PATH $GOENV_ROOT/shims'
+  assert_line 6  'end'
+  assert_line 7  "if not contains '$BATS_TEST_DIRNAME/../libexec/../completions' \$fish_complete_path"
+  assert_line 8  "  set -gp fish_complete_path '$BATS_TEST_DIRNAME/../libexec/../completions'"
+  assert_line 9  'end'
+  assert_line 10 'command goenv rehash 2>/dev/null'
+  assert_line 11 'function goenv'
+  assert_line 12 '  set command $argv[1]'
+  assert_line 13 '  set -e argv[1]'
+  assert_line 14 '  switch "$command"'
+  assert_line 15 '  case hook rehash shell'
+  assert_line 16 '    source (goenv "sh-$command" $argv|psub)'
+  assert_line 17 "  case '*'"
+  assert_line 18 '    command goenv "$command" $argv'
+  assert_line 19 '  end'
+  assert_line 20 'end'
+
+  assert_success
+}
+
+@test "prints bootstrap script with auto-completion when '-' and 'pwsh' are specified" {
+  run goenv-init - pwsh
+
+  assert_success_out <<OUT
+\$env:GOENV_SHELL = 'pwsh'
+\$env:GOENV_ROOT = '$GOENV_ROOT'
+\$goenv_shims = Join-Path \$env:GOENV_ROOT shims
+if ((\$env:PATH -split [IO.Path]::PathSeparator) -notcontains \$goenv_shims) {
+  \$env:PATH = \$env:PATH + [IO.Path]::PathSeparator + \$goenv_shims
+}
+Remove-Variable goenv_shims
+. '$BATS_TEST_DIRNAME/../libexec/../completions/goenv.pwsh'
+& (Get-Command goenv -CommandType Application | Select-Object -First 1) rehash 2>\$null
+function goenv {
+  \$goenv = Get-Command goenv -CommandType Application | Select-Object -First 1
+  if (\$args.Count -gt 0 -and @('hook', 'rehash', 'shell') -contains \$args[0]) {
+    \$command, \$rest = \$args
+    Invoke-Expression ((& \$goenv "sh-\$command" @rest) -join "\`n")
+  } else {
+    & \$goenv @args
+  }
+}
+goenv rehash --only-manage-paths
+OUT
+}
+
+@test "treats 'powershell' as 'pwsh' when '-' and 'powershell' are specified" {
+  run goenv-init - powershell
 
   assert_success
+  assert_line 0 "\$env:GOENV_SHELL = 'pwsh'"
 }
 
 @test "prints bootstrap script without auto-completion when '-' and 'ksh' are specified" {
@@ -224,11 +299,13 @@ OUT
   assert_line 10 '    shift'
   assert_line 11 '  fi'
   assert_line 12 '  case "$command" in'
-  assert_line 13 '  rehash|shell)'
+  assert_line 13 '  hook|rehash|shell)'
   assert_line 14 '    eval "$(goenv "sh-$command" "$@")";;'
-  assert_line 15 '  *)'
-  assert_line 16 '    command goenv "$command" "$@";;'
-  assert_line 17 '  esac'
+  assert_line 15 '  install|uninstall)'
+  assert_line 16 '    command goenv "$command" "$@" && { hash -r 2>/dev/null || true; };;'
+  assert_line 17 '  *)'
+  assert_line 18 '    command goenv "$command" "$@";;'
+  assert_line 19 '  esac'
 
   assert_success
 }
@@ -250,13 +327,239 @@ OUT
   assert_line 10 '    shift'
   assert_line 11 '  fi'
   assert_line 12 '  case "$command" in'
-  assert_line 13 '  rehash|shell)'
+  assert_line 13 '  hook|rehash|shell)'
   assert_line 14 '    eval "$(goenv "sh-$command" "$@")";;'
-  assert_line 15 '  *)'
-  assert_line 16 '    command goenv "$command" "$@";;'
-  assert_line 17 '  esac'
-  assert_line 18 '}'
+  assert_line 15 '  install|uninstall)'
+  assert_line 16 '    command goenv "$command" "$@" && { hash -r 2>/dev/null || true; };;'
+  assert_line 17 '  *)'
+  assert_line 18 '    command goenv "$command" "$@";;'
+  assert_line 19 '  esac'
+  assert_line 20 '}'
+
+  assert_success
+}
+
+
+@test "prints POSIX bootstrap script when '-' and 'dash' are specified" {
+  run goenv-init - dash
+
+  assert_line 0  'export GOENV_SHELL=dash'
+  assert_line 1  "export GOENV_ROOT=$GOENV_ROOT"
+  assert_line 2  'if [ "${PATH#*$GOENV_ROOT/shims}" = "${PATH}" ]; then'
+  assert_line 3  '  export PATH="$PATH:$GOENV_ROOT/shims"'
+  assert_line 4  'fi'
+  assert_line 5  'command goenv rehash 2>/dev/null'
+  assert_line 6  'goenv() {'
+  assert_line 7  '  goenv_command="$1"'
+  assert_line 8  '  if [ "$#" -gt 0 ]; then'
+  assert_line 9  '    shift'
+  assert_line 10 '  fi'
+  assert_line 11 '  case "$goenv_command" in'
+  assert_line 12 '  hook|rehash|shell)'
+  assert_line 13 '    eval "$(command goenv "sh-$goenv_command" "$@")";;'
+  assert_line 14 '  install|uninstall)'
+  assert_line 15 '    command goenv "$goenv_command" "$@" && { hash -r 2>/dev/null || true; };;'
+  assert_line 16 '  *)'
+  assert_line 17 '    command goenv "$goenv_command" "$@";;'
+  assert_line 18 '  esac'
+  assert_line 19 '}'
+  assert_line 20 'goenv rehash --only-manage-paths'
+
+  assert_success
+}
+
+@test "prints POSIX bootstrap script that dash can parse when '-' and 'sh' are specified" {
+  if ! command -v dash >/dev/null; then
+    skip "dash is not installed"
+  fi
+
+  run goenv-init - sh
+  assert_success
+
+  run dash -n -c "$output"
+  assert_success
+}
+
+@test "treats 'busybox' as 'ash' when '-' and 'busybox' are specified" {
+  run goenv-init - busybox
+
+  assert_success
+  assert_line 0 'export GOENV_SHELL=ash'
+  refute_line '  local command'
+}
 
+@test "prints only environment and PATH setup when '-' and '--shims-only' are specified" {
+  run goenv-init - --shims-only sh
+
+  assert_success_out <<OUT
+export GOENV_SHELL=sh
+export GOENV_ROOT=$GOENV_ROOT
+if [ "\${PATH#*\$GOENV_ROOT/shims}" = "\${PATH}" ]; then
+  export PATH="\$PATH:\$GOENV_ROOT/shims"
+fi
+command goenv rehash 2>/dev/null
+OUT
+}
+
+@test "adds 'GOENV_SHIMS_DIR' to PATH when it is not the default shims directory" {
+  export GOENV_SHIMS_DIR="${HOME}/.local/share/goenv/shims"
+
+  run goenv-init - bash
+
+  assert_success
+  assert_line 2 "export GOENV_SHIMS_DIR=${GOENV_SHIMS_DIR}"
+  assert_line 3 'if [ "${PATH#*$GOENV_SHIMS_DIR}" = "${PATH}" ]; then'
+  assert_line 4 '  export PATH="$PATH:$GOENV_SHIMS_DIR"'
+  assert [ -d "${GOENV_SHIMS_DIR}" ]
+  assert [ ! -d "${GOENV_ROOT}/shims" ]
+}
+
+@test "adds 'GOENV_SHIMS_DIR' to PATH in fish when it is not the default shims directory" {
+  export GOENV_SHIMS_DIR="${HOME}/.local/share/goenv/shims"
+
+  run goenv-init - fish
+
+  assert_success
+  assert_line 2 "set -gx GOENV_SHIMS_DIR ${GOENV_SHIMS_DIR}"
+  assert_line 3 'if functions -q fish_add_path'
+  assert_line 4 '  fish_add_path --append --path $GOENV_SHIMS_DIR'
+  assert_line 5 'else if not contains $GOENV_SHIMS_DIR $PATH'
+  assert_line 6 '  set -gx PATH $PATH $GOENV_SHIMS_DIR'
+}
+
+@test "swaps in the allowed shims directory on directory changes when 'GOENV_ALLOWED_SHIMS' is 1" {
+  GOENV_ALLOWED_SHIMS=1 run goenv-init - bash
+  assert_success
+  assert_line '_goenv_allowed_shims() {'
+  assert_line '  PROMPT_COMMAND="_goenv_allowed_shims;${PROMPT_COMMAND:-}"'
+
+  GOENV_ALLOWED_SHIMS=1 run goenv-init - zsh
+  assert_success
+  assert_line 'autoload -Uz add-zsh-hook && add-zsh-hook chpwd _goenv_allowed_shims'
+
+  run goenv-init - bash
+  assert_success
+  refute_line '_goenv_allowed_shims() {'
+}
+
+@test "tells about version switches on directory changes when 'GOENV_VERBOSE_SWITCH' is 1" {
+  GOENV_VERBOSE_SWITCH=1 run goenv-init - bash
+  assert_success
+  assert_line '  _goenv_hook_cd() {'
+  assert_line '    PROMPT_COMMAND="_goenv_hook_cd;${PROMPT_COMMAND:-}"'
+
+  GOENV_VERBOSE_SWITCH=1 run goenv-init - zsh
+  assert_success
+  assert_line '  autoload -Uz add-zsh-hook && add-zsh-hook chpwd _goenv_hook_cd'
+
+  GOENV_VERBOSE_SWITCH=1 run goenv-init - fish
+  assert_success
+  assert_line '  function _goenv_hook_cd --on-variable PWD'
+
+  run goenv-init - bash
+  assert_success
+  refute_line '  _goenv_hook_cd() {'
+}
+
+@test "tells about version switches in an interactive bash" {
+  mkdir -p "${GOENV_ROOT}/versions/1.21.5" "${GOENV_ROOT}/versions/1.22.3" "${GOENV_TEST_DIR}/project"
+  echo "1.21.5" > "${GOENV_ROOT}/version"
+  echo "1.22.3" > "${GOENV_TEST_DIR}/project/.go-version"
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv" <<SH
+#!/bin/sh
+exec "${BATS_TEST_DIRNAME}/../libexec/goenv" "\$@"
+SH
+  cd "$GOENV_TEST_DIR"
+
+  run bash --norc -ic 'eval "$(GOENV_VERBOSE_SWITCH=1 goenv init - bash)"; cd project; _goenv_hook_cd; cd ..; _goenv_hook_cd; _goenv_hook_cd' 2>&1
   assert_success
+  assert_line "goenv: switched to 1.22.3 via .go-version"
+  assert_line "goenv: switched to 1.21.5 via ${GOENV_ROOT}/version"
+  [ "$(echo "$output" | grep -c "^goenv: switched")" -eq 2 ]
+}
+
+@test "loads goenv in the startup files of the login shell and of the other shells that have one" {
+  export GOENV_ROOT="${HOME}/.goenv" SHELL=/bin/zsh
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv" <<SH
+#!/bin/sh
+SH
+  mkdir -p "${HOME}/.config/fish"
+  echo "set -g fish_greeting" > "${HOME}/.config/fish/config.fish"
+  printf 'init.setup: y\n' > "${GOENV_TEST_DIR}/answers.yaml"
+
+  GOENV_ANSWERS="${GOENV_TEST_DIR}/answers.yaml" run goenv-init --setup
+  assert_success_out <<OUT
+zsh: Load goenv in ${HOME}/.zshrc? (y/N) y
+zsh: added goenv to ${HOME}/.zshrc, open a new shell to use it
+fish: Load goenv in ${HOME}/.config/fish/config.fish? (y/N) y
+fish: added goenv to ${HOME}/.config/fish/config.fish, open a new shell to use it
+OUT
+  assert_equal "# >>> goenv >>>
+# Added by 'goenv init --setup', run it again to update this block.
+eval \"\$(goenv init -)\"
+# <<< goenv <<<" "$(cat "${HOME}/.zshrc")"
+  assert_equal "set -g fish_greeting
+
+# >>> goenv >>>
+# Added by 'goenv init --setup', run it again to update this block.
+status --is-interactive; and source (goenv init -|psub)
+# <<< goenv <<<" "$(cat "${HOME}/.config/fish/config.fish")"
+
+  GOENV_ANSWERS="${GOENV_TEST_DIR}/answers.yaml" run goenv-init --setup zsh
+  assert_success "zsh: ${HOME}/.zshrc already loads goenv"
 }
 
+@test "moves goenv from older startup files and sets GOENV_ROOT and PATH when needed" {
+  mkdir -p "$HOME"
+  cat > "${HOME}/.zshenv" <<'ZSH'
+export EDITOR=vi
+
+# Load goenv
+eval "$(goenv init -)"
+ZSH
+  printf 'init.setup: y\n' > "${GOENV_TEST_DIR}/answers.yaml"
+
+  GOENV_ANSWERS="${GOENV_TEST_DIR}/answers.yaml" run goenv-init --setup zsh
+  assert_success_out <<OUT
+zsh: Load goenv in ${HOME}/.zshrc? (y/N) y
+zsh: moved the goenv setup from ${HOME}/.zshenv
+zsh: updated goenv in ${HOME}/.zshrc, open a new shell to use it
+OUT
+  assert_equal "export EDITOR=vi" "$(cat "${HOME}/.zshenv")"
+  assert_equal "# >>> goenv >>>
+# Added by 'goenv init --setup', run it again to update this block.
+export GOENV_ROOT=\"${GOENV_ROOT}\"
+export PATH=\"$(cd "${BATS_TEST_DIRNAME}/../bin" && pwd):\$PATH\"
+eval \"\$(goenv init -)\"
+# <<< goenv <<<" "$(cat "${HOME}/.zshrc")"
+}
+
+@test "removes goenv from the startup files with '--remove'" {
+  mkdir -p "$HOME"
+  cat > "${HOME}/.bashrc" <<'BASH'
+alias ll='ls -l'
+# >>> goenv >>>
+eval "$(goenv init -)"
+# <<< goenv <<<
+BASH
+  echo 'eval "$(goenv init -)"' > "${HOME}/.profile"
+  printf 'init.remove: y\n' > "${GOENV_TEST_DIR}/answers.yaml"
+
+  GOENV_ANSWERS="${GOENV_TEST_DIR}/answers.yaml" run goenv-init --setup --remove bash
+  assert_success_out <<OUT
+bash: Remove goenv from ${HOME}/.bashrc? (y/N) y
+bash: removed goenv from ${HOME}/.bashrc
+bash: Remove goenv from ${HOME}/.profile? (y/N) y
+bash: removed goenv from ${HOME}/.profile
+OUT
+  assert_equal "alias ll='ls -l'" "$(cat "${HOME}/.bashrc")"
+  assert [ ! -s "${HOME}/.profile" ]
+
+  run goenv-init --setup --remove bash
+  assert_success "bash: goenv isn't loaded in its startup files"
+}
+
+@test "fails to set up an unknown shell" {
+  run goenv-init --setup magicshell
+  assert_failure "goenv: can't set up the unknown shell 'magicshell'"
+}
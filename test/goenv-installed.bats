@@ -101,3 +101,27 @@ OUT
   run goenv-installed 1.2.4
   assert_failure "goenv: version '1.2.4' not installed"
 }
+
+@test "goenv installed sets latest version when a 'x' selector is given" {
+  mkdir -p "${GOENV_ROOT}/versions/1.2.10"
+  mkdir -p "${GOENV_ROOT}/versions/1.2.9"
+  mkdir -p "${GOENV_ROOT}/versions/1.3.11"
+  run goenv-installed 1.2.x
+  assert_success "1.2.10"
+
+  run goenv-installed 1.x
+  assert_success "1.3.11"
+
+  run goenv-installed 1.4.x
+  assert_failure "goenv: version '1.4.x' not installed"
+}
+
+@test "goenv installed skips release candidates with 'latest:stable' but not with 'latest:rc'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.23.4"
+  mkdir -p "${GOENV_ROOT}/versions/1.24rc1"
+  run goenv-installed latest:stable
+  assert_success "1.23.4"
+
+  run goenv-installed latest:rc
+  assert_success "1.24rc1"
+}
@@ -10,8 +10,10 @@ setup() {
 @test "has usage instructions" {
   run goenv-help --usage local
   assert_success_out <<OUT
-Usage: goenv local [<version>]
+Usage: goenv local [--yes] [--sync-gomod] [<version>]
        goenv local --unset
+       goenv local --isolated-gopath
+       goenv local [--yes] --shared-gopath
 OUT
 }
 
@@ -42,16 +44,26 @@ OUT
 @test "removes '.go-version' in current dir when '--unset' argument is given and there's a '.go-version' file in current dir" {
   touch .go-version
   run goenv-local --unset
-  assert_success ""
+  assert_success "goenv: now using system (set by ${GOENV_ROOT}/version)"
   assert [ ! -e .go-version ]
 }
 
 @test "succeeds and does nothing when '--unset' argument is given and there's no '.go-version' file in current dir" {
   run goenv-local --unset
-  assert_success ""
+  assert_success "goenv: now using system (set by ${GOENV_ROOT}/version)"
   assert [ ! -e .go-version ]
 }
 
+@test "shows the version of the parent directory used after '--unset'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5"
+  echo "1.22.5" > ../.go-version
+  echo "1.21.0" > .go-version
+
+  run goenv-local --unset
+
+  assert_success "goenv: now using 1.22.5 (set by ${GOENV_TEST_DIR}/.go-version)"
+}
+
 @test "prints local version '.go-version' file in parent directory when no arguments are given" {
   echo "1.2.3" > .go-version
   mkdir -p "subdir"
@@ -151,3 +163,111 @@ OUT
 
   assert [ "$(cat .go-version)" = "1.2.3" ]
 }
+
+@test "sets the toolchain of go.mod with '--sync-gomod'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5"
+  cat > go.mod <<'OUT'
+module example.com/myproject
+
+go 1.22
+
+require example.com/dep v1.0.0
+OUT
+
+  run goenv-local --sync-gomod 1.22.5
+  assert_success ""
+  assert_equal "1.22.5" "$(cat .go-version)"
+  assert_equal "module example.com/myproject
+
+go 1.22
+
+toolchain go1.22.5
+
+require example.com/dep v1.0.0" "$(cat go.mod)"
+}
+
+@test "replaces the toolchain of go.mod when 'GOENV_SYNC_GOMOD' is 1" {
+  mkdir -p "${GOENV_ROOT}/versions/1.23.4"
+  printf 'module example.com/myproject\n\ngo 1.22\n\ntoolchain go1.22.5\n' > go.mod
+
+  GOENV_SYNC_GOMOD=1 run goenv-local 1.23.4
+  assert_success ""
+  assert_equal "module example.com/myproject
+
+go 1.22
+
+toolchain go1.23.4" "$(cat go.mod)"
+}
+
+@test "syncs go.mod to the local version with '--sync-gomod' and no version" {
+  echo "1.22.5" > .go-version
+  printf 'module example.com/myproject\n\ngo 1.22\n' > go.mod
+
+  run goenv-local --sync-gomod
+  assert_success ""
+  assert_equal "toolchain go1.22.5" "$(tail -1 go.mod)"
+}
+
+@test "leaves go.mod alone without '--sync-gomod'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5"
+  printf 'module example.com/myproject\n\ngo 1.22\n' > go.mod
+
+  run goenv-local 1.22.5
+  assert_success ""
+  assert_equal "go 1.22" "$(tail -1 go.mod)"
+}
+
+@test "gives the project its own GOPATH with '--isolated-gopath'" {
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo "\$GOPATH"
+SH
+  echo "1.22.5" > .go-version
+  mkdir -p src
+
+  run goenv-local --isolated-gopath
+  assert_success "goenv: commands run through goenv in '${PWD}' use the GOPATH '${PWD}/.goenv/gopath'"
+  assert [ -d "${PWD}/.goenv/gopath" ]
+
+  cd src
+  GOENV_DIR="$PWD" run goenv-exec go
+  assert_success "${GOENV_TEST_DIR}/myproject/.goenv/gopath"
+}
+
+@test "finds the tools installed in the isolated GOPATH" {
+  create_executable "1.22.5" "go"
+  echo "1.22.5" > .go-version
+  goenv-local --isolated-gopath
+  create_executable "${PWD}/.goenv/gopath/bin" "gopls"
+
+  run goenv-which gopls
+  assert_success "${PWD}/.goenv/gopath/bin/gopls"
+}
+
+@test "removes the isolated GOPATH with '--shared-gopath'" {
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo "\$GOPATH"
+SH
+  echo "1.22.5" > .go-version
+  goenv-local --isolated-gopath
+  mkdir -p .goenv/gopath/pkg/mod/example.com/m@v1.0.0
+  touch .goenv/gopath/pkg/mod/example.com/m@v1.0.0/go.mod
+  chmod -R a-w .goenv/gopath/pkg
+
+  run goenv-local --shared-gopath <<< "n"
+  assert_failure
+  assert [ -d .goenv/gopath ]
+
+  run goenv-local --yes --shared-gopath
+  assert_success "goenv: commands run through goenv in '${PWD}' use the GOPATH of their version again"
+  assert [ ! -e .goenv ]
+
+  GOENV_DIR="$PWD" run goenv-exec go
+  assert_success "${HOME}/go/1.22.5"
+}
+
+@test "fails to remove an isolated GOPATH that doesn't exist" {
+  run goenv-local --shared-gopath --yes
+  assert_failure "goenv: '${PWD}' has no isolated GOPATH"
+}
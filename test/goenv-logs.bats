@@ -0,0 +1,95 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "${GOENV_ROOT}/watchd"
+  log="${GOENV_ROOT}/watchd/log"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage logs
+  assert_success_out <<OUT
+Usage: goenv logs show [<log>]
+       goenv logs tail [<log>]
+       goenv logs clean [--all]
+OUT
+}
+
+@test "fails and prints usage when no subcommand is given" {
+  run goenv-logs
+  assert_failure
+  assert_line 0 "Usage: goenv logs show [<log>]"
+}
+
+@test "fails to show an unknown log" {
+  run goenv-logs show build
+  assert_failure "goenv: unknown log 'build', the logs are: watch watchd"
+}
+
+@test "fails to show a log that was not written yet" {
+  run goenv-logs show
+  assert_failure "goenv: no watchd log was written yet"
+}
+
+@test "rotates a log that grew too large and shows it with its rotated logs" {
+  echo "first" > "$log"
+
+  GOENV_LOG_MAX_SIZE=0 run goenv-logs clean
+  assert_success "goenv: rotated the watchd log"
+  assert [ -f "${log}.1.gz" ]
+  assert [ ! -s "$log" ]
+
+  echo "second" > "$log"
+  GOENV_LOG_MAX_SIZE=0 run goenv-logs clean
+  assert_success "goenv: rotated the watchd log"
+  echo "third" > "$log"
+
+  run goenv-logs show watchd
+  assert_success_out <<OUT
+first
+second
+third
+OUT
+}
+
+@test "does not rotate a log under the size limit" {
+  echo "first" > "$log"
+
+  run goenv-logs clean
+  assert_success ""
+  assert [ ! -f "${log}.1.gz" ]
+}
+
+@test "removes rotated logs beyond 'GOENV_LOG_KEEP'" {
+  for i in 1 2 3; do
+    echo "$i" > "$log"
+    GOENV_LOG_MAX_SIZE=0 goenv-logs clean >/dev/null
+  done
+
+  GOENV_LOG_KEEP=1 run goenv-logs clean
+  assert_success "goenv: removed 2 old watchd log(s)"
+  assert [ -f "${log}.1.gz" ]
+  assert [ ! -f "${log}.2.gz" ]
+}
+
+@test "removes rotated logs older than 'GOENV_LOG_MAX_AGE' days" {
+  echo "old" > "$log"
+  GOENV_LOG_MAX_SIZE=0 goenv-logs clean >/dev/null
+  touch -t 200001010000 "${log}.1.gz"
+
+  run goenv-logs clean
+  assert_success "goenv: removed 1 old watchd log(s)"
+  assert [ ! -f "${log}.1.gz" ]
+}
+
+@test "removes all logs with --all" {
+  echo "first" > "$log"
+  GOENV_LOG_MAX_SIZE=0 goenv-logs clean >/dev/null
+  echo "second" > "$log"
+
+  run goenv-logs clean --all
+  assert_success "goenv: removed the watchd log"
+  assert [ ! -f "${log}.1.gz" ]
+  assert [ ! -s "$log" ]
+}
@@ -0,0 +1,131 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+# Pretends to be an Apple Silicon Mac whose `file' tells binaries marked
+# with `x86_64' apart from arm64 ones.
+setup() {
+  mkdir -p "${GOENV_TEST_DIR}/bin"
+  cat > "${GOENV_TEST_DIR}/bin/uname" <<'SH'
+#!/bin/sh
+case "$1" in
+-s ) echo Darwin ;;
+-m ) echo arm64 ;;
+esac
+SH
+  cat > "${GOENV_TEST_DIR}/bin/file" <<'SH'
+#!/bin/sh
+if grep -q x86_64 "$2"; then
+  echo "Mach-O 64-bit executable x86_64"
+else
+  echo "Mach-O 64-bit executable arm64"
+fi
+SH
+  cat > "${GOENV_TEST_DIR}/bin/goenv-install" <<SH
+#!/bin/sh
+echo "install \$FORCE_DARWIN_ARCH \$1" >> "${GOENV_TEST_DIR}/log"
+mkdir -p "${GOENV_ROOT}/versions/\$1/bin"
+cp "${GOENV_TEST_DIR}/go" "${GOENV_ROOT}/versions/\$1/bin/go"
+SH
+  create_go "${GOENV_TEST_DIR}" arm64
+  chmod +x "${GOENV_TEST_DIR}/bin/"*
+}
+
+# Creates a fake `go' for an architecture that knows which package every
+# tool was built from and installs tools for the same architecture.
+create_go() {
+  mkdir -p "$1"
+  cat > "${1}/go" <<SH
+#!/bin/sh
+# $2
+if [ "\$1 \$2" = "version -m" ]; then
+  printf '\tpath\texample.com/cmd/%s\n\tmod\texample.com\tv1.2.0\n' "\${3##*/}"
+elif [ "\$1" = "install" ]; then
+  echo "go install \$2 \$GOARCH" >> "${GOENV_TEST_DIR}/log"
+  name="\${2##*/}"
+  mkdir -p "\$GOPATH/bin"
+  echo "# arm64" > "\$GOPATH/bin/\${name%@*}"
+  chmod +x "\$GOPATH/bin/\${name%@*}"
+fi
+SH
+  chmod +x "${1}/go"
+}
+
+create_tool() {
+  mkdir -p "${HOME}/go/${1}/bin"
+  echo "# ${3}" > "${HOME}/go/${1}/bin/${2}"
+  chmod +x "${HOME}/go/${1}/bin/${2}"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage migrate-arch
+  assert_success_out <<OUT
+Usage: goenv migrate-arch [--yes]
+       goenv migrate-arch --list
+OUT
+}
+
+@test "lists amd64 Go versions and tools" {
+  create_go "${GOENV_ROOT}/versions/1.20.5/bin" x86_64
+  create_go "${GOENV_ROOT}/versions/1.22.5/bin" arm64
+  create_tool 1.22.5 lint x86_64
+  create_tool 1.22.5 fmt arm64
+
+  run goenv-migrate-arch --list
+  assert_success_out <<OUT
+1.20.5 go
+1.22.5 lint
+OUT
+}
+
+@test "lists nothing when not on Apple Silicon" {
+  create_go "${GOENV_ROOT}/versions/1.20.5/bin" x86_64
+  printf '#!/bin/sh\necho Linux\n' > "${GOENV_TEST_DIR}/bin/uname"
+
+  run goenv-migrate-arch --list
+  assert_success ""
+
+  run goenv-migrate-arch
+  assert_success "goenv: not an Apple Silicon Mac, there's nothing to migrate"
+}
+
+@test "installs amd64 versions again for arm64 and rebuilds their tools" {
+  create_go "${GOENV_ROOT}/versions/1.20.5/bin" x86_64
+  create_tool 1.20.5 lint x86_64
+  create_go "${GOENV_ROOT}/versions/1.22.5/bin" arm64
+  create_tool 1.22.5 vet x86_64
+
+  run goenv-migrate-arch --yes
+  assert_success
+  assert_line "Migrated to arm64: 1.20.5. Pinned versions keep their names, so nothing else changes."
+
+  run cat "${GOENV_TEST_DIR}/log"
+  assert_success_out <<OUT
+install arm64 1.20.5
+go install example.com/cmd/lint@v1.2.0 arm64
+go install example.com/cmd/vet@v1.2.0 arm64
+OUT
+  run goenv-migrate-arch --list
+  assert_success ""
+  [ ! -d "${GOENV_ROOT}/migrate-arch" ]
+}
+
+@test "keeps the amd64 versions unless removing them is confirmed" {
+  create_go "${GOENV_ROOT}/versions/1.20.5/bin" x86_64
+  echo "migrate-arch.remove: n" > "${GOENV_TEST_DIR}/answers.yaml"
+
+  GOENV_ANSWERS="${GOENV_TEST_DIR}/answers.yaml" run goenv-migrate-arch
+  assert_success
+  assert_line "Kept the amd64 versions in '${GOENV_ROOT}/migrate-arch', remove them once you're sure."
+  grep -q x86_64 "${GOENV_ROOT}/migrate-arch/1.20.5/bin/go"
+}
+
+@test "restores a version that fails to install for arm64" {
+  create_go "${GOENV_ROOT}/versions/1.20.5/bin" x86_64
+  printf '#!/bin/sh\nexit 1\n' > "${GOENV_TEST_DIR}/bin/goenv-install"
+
+  run goenv-migrate-arch --yes
+  assert_failure
+  assert_line "goenv: failed to install 1.20.5 for arm64, kept the amd64 version"
+  grep -q x86_64 "${GOENV_ROOT}/versions/1.20.5/bin/go"
+}
@@ -0,0 +1,118 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  old="${GOENV_TEST_DIR}/old"
+  mkdir -p "${old}/versions/1.21.13/bin" "${old}/versions/1.22.5/bin"
+  touch "${old}/versions/1.21.13/bin/go" "${old}/versions/1.22.5/bin/go"
+  chmod +x "${old}/versions/1.21.13/bin/go" "${old}/versions/1.22.5/bin/go"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage migrate
+  assert_success "Usage: goenv migrate [--dry-run] [<root>]"
+}
+
+@test "fails and prints usage with an unknown option" {
+  run goenv-migrate --all
+  assert_failure "Usage: goenv migrate [--dry-run] [<root>]"
+}
+
+@test "fails when the root isn't a goenv installation" {
+  mkdir -p "${GOENV_TEST_DIR}/empty"
+
+  run goenv-migrate "${GOENV_TEST_DIR}/empty"
+  assert_failure "goenv: '${GOENV_TEST_DIR}/empty' doesn't look like a goenv installation, it has no versions"
+}
+
+@test "moves the versions that aren't installed already and creates the shims" {
+  create_executable "1.22.5" "go"
+
+  run goenv-migrate "$old"
+  assert_success_out <<OUT
+goenv: migrating '${old}' into '${GOENV_ROOT}'
+  move version 1.21.13
+  keep version 1.22.5, it's installed already
+  create the shims again
+OUT
+  assert [ -x "${GOENV_ROOT}/versions/1.21.13/bin/go" ]
+  assert [ ! -e "${old}/versions/1.21.13" ]
+  assert [ -e "${old}/versions/1.22.5" ]
+  assert [ -x "${GOENV_ROOT}/shims/go" ]
+}
+
+@test "copies the global version and plugins" {
+  echo "1.22.5" > "${old}/version"
+  mkdir -p "${old}/plugins/go-build" "${old}/plugins/goenv-doctor-extra/bin"
+
+  run goenv-migrate "$old"
+  assert_success
+  assert_line "  copy plugin goenv-doctor-extra"
+  assert_line "  copy the global version (1.22.5)"
+  refute_line "  copy plugin go-build"
+  assert_equal "1.22.5" "$(cat "${GOENV_ROOT}/version")"
+  assert [ -d "${GOENV_ROOT}/plugins/goenv-doctor-extra/bin" ]
+}
+
+@test "copies the global file of the oldest goenv releases" {
+  echo "1.21.13" > "${old}/global"
+
+  run goenv-migrate "$old"
+  assert_success
+  assert_line "  copy the global version (1.21.13)"
+  assert_equal "1.21.13" "$(cat "${GOENV_ROOT}/version")"
+}
+
+@test "keeps the global version that's set" {
+  mkdir -p "$GOENV_ROOT"
+  echo "1.22.5" > "${old}/version"
+  echo "1.21.13" > "${GOENV_ROOT}/version"
+
+  run goenv-migrate "$old"
+  assert_success
+  refute_line "  copy the global version (1.22.5)"
+  assert_equal "1.21.13" "$(cat "${GOENV_ROOT}/version")"
+}
+
+@test "lists the shell startup files that point GOENV_ROOT at the old root" {
+  old="${HOME}/.goenv-old"
+  mkdir -p "${old}/versions/1.22.5"
+  cat > "${HOME}/.bashrc" <<SH
+export PATH="\$HOME/bin:\$PATH"
+export GOENV_ROOT="\$HOME/.goenv-old"
+SH
+
+  run goenv-migrate "$old"
+  assert_success
+  assert_line "goenv: update GOENV_ROOT in ${HOME}/.bashrc:2, it still points at '${old}'"
+}
+
+@test "changes nothing with --dry-run" {
+  echo "1.22.5" > "${old}/version"
+
+  run goenv-migrate --dry-run "$old"
+  assert_success_out <<OUT
+goenv: migrating '${old}' into '${GOENV_ROOT}' (dry run, nothing is changed)
+  move version 1.21.13
+  move version 1.22.5
+  copy the global version (1.22.5)
+  create the shims again
+OUT
+  assert [ ! -e "${GOENV_ROOT}/versions" ]
+  assert [ ! -e "${GOENV_ROOT}/version" ]
+}
+
+@test "creates the shims of GOENV_ROOT again without a root" {
+  create_executable "1.22.5" "go"
+  mkdir -p "${GOENV_ROOT}/shims"
+  echo "#!/bin/sh" > "${GOENV_ROOT}/shims/go"
+
+  run goenv-migrate
+  assert_success_out <<OUT
+goenv: migrating '${GOENV_ROOT}' into '${GOENV_ROOT}'
+  create the shims again
+OUT
+  run grep -c "goenv" "${GOENV_ROOT}/shims/go"
+  assert_success
+}
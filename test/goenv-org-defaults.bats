@@ -0,0 +1,121 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+  cat > defaults.env <<ENV
+# Organization defaults
+GOFLAGS=-trimpath
+GONOSUMDB=*.corp.example.com
+GOTOOLCHAIN=go{version}
+PATH=/tmp/evil
+ENV
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage org-defaults
+  assert_success "Usage: goenv org-defaults [--refresh]"
+}
+
+@test "prints nothing without 'GOENV_ORG_DEFAULTS'" {
+  run goenv-org-defaults
+  assert_success ""
+}
+
+@test "prints the Go variables of the organization defaults" {
+  GOENV_VERSION=1.22.0 GOENV_ORG_DEFAULTS="${PWD}/defaults.env" run goenv-org-defaults
+  assert_success_out <<OUT
+GOFLAGS=-trimpath
+GONOSUMDB=*.corp.example.com
+GOTOOLCHAIN=go1.22.0
+OUT
+  assert [ -f "${GOENV_ROOT}/org-defaults" ]
+}
+
+@test "uses the cached defaults until they're refreshed" {
+  export GOENV_VERSION=1.22.0 GOENV_ORG_DEFAULTS="${PWD}/defaults.env"
+  run goenv-org-defaults
+  echo "GOFLAGS=-mod=mod" > defaults.env
+
+  run goenv-org-defaults
+  assert_line 0 "GOFLAGS=-trimpath"
+
+  run goenv-org-defaults --refresh
+  assert_success "GOFLAGS=-mod=mod"
+}
+
+@test "fails when the defaults cannot be fetched" {
+  GOENV_ORG_DEFAULTS="${PWD}/missing.env" run goenv-org-defaults
+  assert_failure
+  assert_line "goenv: cannot fetch organization defaults from '${PWD}/missing.env'"
+}
+
+@test "fetches defaults at a URL in the background and retries after a failure" {
+  export GOENV_ORG_DEFAULTS="https://127.0.0.1:9/defaults.env"
+
+  run goenv-org-defaults
+  assert_success ""
+  assert [ -f "${GOENV_ROOT}/org-defaults.attempt" ]
+
+  touch -d "1 minute ago" "${GOENV_ROOT}/org-defaults.attempt" 2>/dev/null ||
+    touch -t "$(date -v-1M +%Y%m%d%H%M)" "${GOENV_ROOT}/org-defaults.attempt"
+  run goenv-org-defaults
+  assert_success ""
+  assert [ "${GOENV_ROOT}/org-defaults.attempt" -ot defaults.env ]
+}
+
+@test "ignores defaults at an http:// URL unless they're signed" {
+  GOENV_ORG_DEFAULTS="http://corp.example.com/defaults.env" run goenv-org-defaults
+  assert_failure "goenv: ignoring organization defaults from 'http://corp.example.com/defaults.env', an http:// URL must be https:// or signed for GOENV_ORG_DEFAULTS_PUBKEY"
+  assert [ ! -e "${GOENV_ROOT}/org-defaults.attempt" ]
+}
+
+@test "requires a valid signature with 'GOENV_ORG_DEFAULTS_PUBKEY'" {
+  command -v openssl >/dev/null || skip "openssl is not installed"
+  openssl genpkey -algorithm RSA -out org.key 2>/dev/null
+  openssl pkey -in org.key -pubout -out org.pub
+  export GOENV_VERSION=1.22.0 GOENV_ORG_DEFAULTS="${PWD}/defaults.env" GOENV_ORG_DEFAULTS_PUBKEY="${PWD}/org.pub"
+
+  run goenv-org-defaults
+  assert_failure "goenv: ignoring organization defaults from '${PWD}/defaults.env', their signature cannot be verified"
+
+  openssl dgst -sha256 -sign org.key -out defaults.env.sig defaults.env
+  run goenv-org-defaults
+  assert_success
+  assert_line 0 "GOFLAGS=-trimpath"
+
+  echo "GOFLAGS=-mod=mod" > defaults.env
+  run goenv-org-defaults --refresh
+  assert_success
+  assert_line 0 "goenv: ignoring organization defaults from '${PWD}/defaults.env', their signature cannot be verified"
+  assert_line 1 "GOFLAGS=-trimpath"
+}
+
+@test "ignores the cached defaults once GOENV_ORG_DEFAULTS points elsewhere" {
+  export GOENV_VERSION=1.22.0
+  GOENV_ORG_DEFAULTS="${PWD}/defaults.env" goenv-org-defaults >/dev/null
+  echo "GOFLAGS=-mod=mod" > other.env
+
+  GOENV_ORG_DEFAULTS="${PWD}/other.env" run goenv-org-defaults
+  assert_success "GOFLAGS=-mod=mod"
+
+  GOENV_ORG_DEFAULTS="https://127.0.0.1:9/defaults.env" run goenv-org-defaults
+  assert_success ""
+  assert [ ! -f "${GOENV_ROOT}/org-defaults" ]
+}
+
+@test "ignores the cached defaults once GOENV_ORG_DEFAULTS_PUBKEY names another key" {
+  command -v openssl >/dev/null || skip "openssl is not installed"
+  openssl genpkey -algorithm RSA -out org.key 2>/dev/null
+  openssl pkey -in org.key -pubout -out org.pub
+  openssl genpkey -algorithm RSA -out other.key 2>/dev/null
+  openssl pkey -in other.key -pubout -out other.pub
+  openssl dgst -sha256 -sign org.key -out defaults.env.sig defaults.env
+  export GOENV_VERSION=1.22.0 GOENV_ORG_DEFAULTS="${PWD}/defaults.env"
+  GOENV_ORG_DEFAULTS_PUBKEY="${PWD}/org.pub" goenv-org-defaults >/dev/null
+
+  GOENV_ORG_DEFAULTS_PUBKEY="${PWD}/other.pub" run goenv-org-defaults
+  assert_failure "goenv: ignoring organization defaults from '${PWD}/defaults.env', their signature cannot be verified"
+}
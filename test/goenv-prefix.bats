@@ -5,7 +5,7 @@ load test_helper
 @test "has usage instructions" {
   run goenv-help --usage prefix
   assert_success_out <<OUT
-Usage: goenv prefix [<version>]
+Usage: goenv prefix [--bin|--gopath|--gocache] [<version>]
 OUT
 }
 
@@ -14,6 +14,9 @@ OUT
   mkdir -p "${GOENV_ROOT}/versions/1.10.9"
   run goenv-prefix --complete
   assert_success_out <<OUT
+--bin
+--gopath
+--gocache
 latest
 system
 1.10.9
@@ -130,3 +133,45 @@ OUT
   run goenv-prefix 1.9
   assert_failure "goenv: version '1.9' not installed"
 }
+
+@test "displays the bin directory of a version with '--bin'" {
+  create_executable "1.22.5" "go"
+
+  run goenv-prefix --bin 1.22
+  assert_success "${GOENV_ROOT}/versions/1.22.5/bin"
+}
+
+@test "displays the GOPATH goenv sets for a version with '--gopath'" {
+  create_executable "1.22.5" "go"
+
+  GOENV_GOPATH_EXTRA="/srv/gopath" run goenv-prefix --gopath 1.22
+  assert_success "${HOME}/go/1.22.5"
+
+  GOENV_GOPATH_PREFIX="/srv/go" run goenv-prefix --gopath 1.22.5
+  assert_success "/srv/go/1.22.5"
+}
+
+@test "displays the build cache goenv sets for a version with '--gocache'" {
+  create_executable "1.22.5" "go"
+
+  GOENV_GOCACHE_DIR="${GOENV_TEST_DIR}/cache" run goenv-prefix --gocache 1.22.5
+  assert_success "${GOENV_TEST_DIR}/cache/1.22.5-$(goenv-cache --platform)"
+}
+
+@test "displays the build cache of go itself with '--gocache' when goenv doesn't set one" {
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+[ "\$*" = "env GOCACHE" ] && echo "${HOME}/.cache/go-build"
+SH
+
+  run goenv-prefix --gocache 1.22.5
+  assert_success "${HOME}/.cache/go-build"
+}
+
+@test "fails to display a directory of several versions" {
+  create_executable "1.21.13" "go"
+  create_executable "1.22.5" "go"
+
+  run goenv-prefix --bin 1.21.13 1.22.5
+  assert_failure "goenv: several versions are selected (1.21.13:1.22.5), name the one to display the bin of"
+}
@@ -0,0 +1,95 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage prompt
+  assert_success_out <<OUT
+Usage: goenv prompt <id> <question>
+       goenv prompt --choose <id> <question> <choice>...
+OUT
+}
+
+@test "fails and prints usage without a question" {
+  run goenv-prompt uninstall.remove
+  assert_failure
+  assert_line 0 "Usage: goenv prompt <id> <question>"
+}
+
+@test "reads the answer from stdin" {
+  run goenv-prompt uninstall.remove "goenv: remove 1.22.0? " <<< "y"
+  assert_success "y"
+}
+
+@test "prints an empty answer at the end of stdin" {
+  run goenv-prompt uninstall.remove "goenv: remove 1.22.0? " < /dev/null
+  assert_success ""
+}
+
+@test "takes the answer from the answers file" {
+  cat > answers.yaml <<YAML
+# Answers for CI
+doctor.fix-ownership: n
+uninstall.remove: "yes"  # remove old versions
+YAML
+
+  GOENV_ANSWERS=answers.yaml run goenv-prompt uninstall.remove "goenv: remove 1.22.0? " < /dev/null
+  assert_success_out <<OUT
+goenv: remove 1.22.0? yes
+yes
+OUT
+}
+
+@test "fails when the answers file has no answer" {
+  echo "doctor.fix-ownership: n" > answers.yaml
+
+  GOENV_ANSWERS=answers.yaml run goenv-prompt uninstall.remove "goenv: remove 1.22.0? " <<< "y"
+  assert_failure "goenv: no answer for 'uninstall.remove' in 'answers.yaml'"
+}
+
+@test "fails when the answers file does not exist" {
+  GOENV_ANSWERS=missing.yaml run goenv-prompt uninstall.remove "goenv: remove 1.22.0? "
+  assert_failure "goenv: answers file 'missing.yaml' does not exist"
+}
+
+@test "takes the answers file from 'goenv --answers'" {
+  echo "uninstall.remove: y" > answers.yaml
+
+  run goenv --answers answers.yaml prompt uninstall.remove "goenv: remove 1.22.0? " < /dev/null
+  assert_success_out <<OUT
+goenv: remove 1.22.0? y
+y
+OUT
+}
+
+@test "reads a choice by its number or itself when stdin is not a terminal" {
+  run goenv-prompt --choose install.version "Install: " 1.23.4 1.22.10 <<< "2"
+  assert_success_out <<OUT
+  1) 1.23.4
+  2) 1.22.10
+Install: 2
+1.22.10
+OUT
+
+  run goenv-prompt --choose install.version "Install: " 1.23.4 1.22.10 <<< "1.23.4"
+  assert_success
+  assert_line 3 "1.23.4"
+}
+
+@test "fails for an answer that isn't one of the choices" {
+  run goenv-prompt --choose install.version "Install: " 1.23.4 1.22.10 <<< "3"
+  assert_failure
+  assert_line 3 "goenv: '3' is not one of the choices"
+
+  echo "install.version: 1.21.0" > answers.yaml
+  GOENV_ANSWERS=answers.yaml run goenv-prompt --choose install.version "Install: " 1.23.4 1.22.10
+  assert_failure_out <<OUT
+Install: 1.21.0
+goenv: the answer for 'install.version' in 'answers.yaml' is not one of the choices
+OUT
+}
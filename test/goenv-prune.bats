@@ -0,0 +1,127 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  for version in 1.21.3 1.22.1 1.22.4 1.22.5; do
+    create_executable "$version" go "#!/bin/sh"
+  done
+  mkdir -p "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv-uninstall" <<SH
+#!/bin/sh
+echo "goenv-uninstall \$*" >> "${GOENV_TEST_DIR}/uninstalled"
+rm -rf "${GOENV_ROOT}/versions/\$2"
+SH
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage prune
+  assert_success_out <<OUT
+Usage: goenv prune --policy [--dry-run]
+       goenv prune --old-patches [--dry-run]
+OUT
+}
+
+@test "fails and prints usage without '--policy'" {
+  run goenv-prune
+  assert_failure
+  assert_line "Usage: goenv prune --policy [--dry-run]"
+}
+
+@test "only prints what would be removed the first time the policy is applied" {
+  run goenv-prune --policy
+  assert_success_out <<OUT
+Retention policy: keep pinned versions, and the latest 2 patch release(s) of each minor version used in the last 90 days
+  keep    1.21.3
+  remove  1.22.1  (not among the latest 2 patch release(s) of 1.22)
+  keep    1.22.4
+  keep    1.22.5
+Nothing was removed, since this is the first time the policy was applied.
+Review the versions above and run 'goenv prune --policy' again to remove them.
+OUT
+  assert [ -d "${GOENV_ROOT}/versions/1.22.1" ]
+}
+
+@test "removes the versions the policy doesn't keep once it was reviewed" {
+  touch "${GOENV_ROOT}/.prune-policy-reviewed"
+
+  run goenv-prune --policy
+  assert_success
+  assert_line "Removed 1 version(s): 1.22.1"
+  assert_equal "goenv-uninstall --force 1.22.1" "$(cat "${GOENV_TEST_DIR}/uninstalled")"
+  assert [ ! -d "${GOENV_ROOT}/versions/1.22.1" ]
+  assert [ -d "${GOENV_ROOT}/versions/1.22.4" ]
+}
+
+@test "does not remove anything with '--dry-run'" {
+  touch "${GOENV_ROOT}/.prune-policy-reviewed"
+
+  run goenv-prune --policy --dry-run
+  assert_success
+  assert_line "  remove  1.22.1  (not among the latest 2 patch release(s) of 1.22)"
+  assert [ -d "${GOENV_ROOT}/versions/1.22.1" ]
+}
+
+@test "keeps versions pinned by the global and local version files" {
+  echo "1.22.1" > "${GOENV_ROOT}/version"
+  echo "1.21" > .go-version
+
+  run goenv-prune --policy --dry-run
+  assert_success_out <<OUT
+Retention policy: keep pinned versions, and the latest 2 patch release(s) of each minor version used in the last 90 days
+  keep    1.21.3  (pinned by ${GOENV_TEST_DIR}/.go-version)
+  keep    1.22.1  (pinned by ${GOENV_ROOT}/version)
+  keep    1.22.4
+  keep    1.22.5
+Nothing to remove.
+OUT
+}
+
+@test "removes versions that weren't used within 'GOENV_PRUNE_UNUSED_DAYS'" {
+  touch -a -d "100 days ago" "${GOENV_ROOT}/versions/1.21.3/bin/go" 2>/dev/null ||
+    touch -a -t "$(date -v-100d +%Y%m%d%H%M)" "${GOENV_ROOT}/versions/1.21.3/bin/go"
+
+  GOENV_PRUNE_KEEP_PATCHES=3 run goenv-prune --policy --dry-run
+  assert_success
+  assert_line "  remove  1.21.3  (not used in the last 90 days)"
+  assert_line "  keep    1.22.1"
+
+  GOENV_PRUNE_UNUSED_DAYS=0 GOENV_PRUNE_KEEP_PATCHES=3 run goenv-prune --policy --dry-run
+  assert_success
+  assert_line "  keep    1.21.3"
+}
+
+@test "tells when versions were last used from the usage log of 'goenv stats'" {
+  touch -a -d "100 days ago" "${GOENV_ROOT}/versions/1.21.3/bin/go" "${GOENV_ROOT}/versions/1.22.1/bin/go" 2>/dev/null ||
+    touch -a -t "$(date -v-100d +%Y%m%d%H%M)" "${GOENV_ROOT}/versions/1.21.3/bin/go" "${GOENV_ROOT}/versions/1.22.1/bin/go"
+  echo "$(($(date +%s) - 86400)) 1.21.3 go" > "${GOENV_ROOT}/usage.log"
+
+  GOENV_PRUNE_KEEP_PATCHES=3 run goenv-prune --policy --dry-run
+  assert_success
+  assert_line "  keep    1.21.3"
+  assert_line "  remove  1.22.1  (not used in the last 90 days)"
+}
+
+@test "keeps versions pinned by projects below 'GOENV_PROJECT_ROOTS'" {
+  mkdir -p "${GOENV_TEST_DIR}/src/app" "${GOENV_TEST_DIR}/src/lib/.git"
+  echo "1.22.1" > "${GOENV_TEST_DIR}/src/app/.go-version"
+  echo "1.22.4" > "${GOENV_TEST_DIR}/src/lib/.git/.go-version"
+
+  GOENV_PROJECT_ROOTS="${GOENV_TEST_DIR}/missing:${GOENV_TEST_DIR}/src" run goenv-prune --old-patches --dry-run
+  assert_success_out <<OUT
+Retention policy: keep pinned versions, and the latest 1 patch release(s) of each minor version
+  keep    1.21.3
+  keep    1.22.1  (pinned by ${GOENV_TEST_DIR}/src/app/.go-version)
+  remove  1.22.4  (not among the latest 1 patch release(s) of 1.22)
+  keep    1.22.5
+OUT
+}
+
+@test "removes old patch releases on the first run with '--old-patches'" {
+  run goenv-prune --old-patches
+  assert_success
+  assert_line "Removed 2 version(s): 1.22.1 1.22.4"
+  assert [ ! -d "${GOENV_ROOT}/versions/1.22.4" ]
+  assert [ -d "${GOENV_ROOT}/versions/1.22.5" ]
+}
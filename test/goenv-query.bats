@@ -0,0 +1,40 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5" "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage query
+  assert_success "Usage: goenv query <template>"
+}
+
+@test "fails and prints usage without a template" {
+  run goenv-query
+  assert_failure "Usage: goenv query <template>"
+}
+
+@test "replaces the fields of the template" {
+  echo "1.22.5" > .go-version
+
+  run goenv-query '{{.Version}} {{.Source}} {{ .GOROOT }}'
+  assert_success "1.22.5 ${GOENV_TEST_DIR}/.go-version ${GOENV_ROOT}/versions/1.22.5"
+}
+
+@test "prints the GOPATH and build cache that 'goenv exec' sets" {
+  GOENV_VERSION=1.22.5 GOENV_GOPATH_PREFIX=/gopath GOENV_GOCACHE_DIR=/cache/ run goenv-query 'GOPATH={{.GOPATH}} GOCACHE={{.GOCACHE}}'
+  assert_success "GOPATH=/gopath/1.22.5 GOCACHE=/cache/1.22.5-$(goenv-cache --platform)"
+}
+
+@test "keeps text that is not a field" {
+  GOENV_VERSION=1.22.5 run goenv-query '{version} {{.Version}}}'
+  assert_success "{version} 1.22.5}"
+}
+
+@test "fails on unknown fields" {
+  GOENV_VERSION=1.22.5 run goenv-query '{{.Version}} {{.Arch}}'
+  assert_failure "goenv: unknown field '.Arch' in template, see 'goenv help query'"
+}
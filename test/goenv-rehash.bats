@@ -5,7 +5,7 @@ load test_helper
 @test "has usage instructions" {
   run goenv-help --usage rehash
   assert_success_out <<OUT
-Usage: goenv rehash
+Usage: goenv rehash [--versioned-aliases] [--verbose]
 OUT
 }
 
@@ -17,6 +17,17 @@ OUT
   rmdir "${GOENV_ROOT}/shims"
 }
 
+@test "creates shims in 'GOENV_SHIMS_DIR' when it is set" {
+  export GOENV_SHIMS_DIR="${HOME}/.local/share/goenv/shims"
+  create_executable "1.11.1" "go"
+
+  run goenv-rehash
+
+  assert_success ""
+  assert [ -x "${GOENV_SHIMS_DIR}/go" ]
+  assert [ ! -d "${GOENV_ROOT}/shims" ]
+}
+
 @test "fails when shims directory at 'GOENV_ROOT/shims' is not writable" {
   if [ "$(whoami)" = "root" ]; then
       skip "running as root. permissions won't matter."
@@ -29,12 +40,33 @@ OUT
   assert_failure "goenv: cannot rehash: ${GOENV_ROOT}/shims isn't writable"
 }
 
-@test "fails when 'GOENV_ROOT/shims/.goenv-shim' is present, meaning rehash in progress or interrupted via SIGKILL" {
+@test "succeeds when a leftover 'GOENV_ROOT/shims/.goenv-shim' is present" {
+  create_executable "1.11.1" "go"
   mkdir -p "${GOENV_ROOT}/shims"
   touch "${GOENV_ROOT}/shims/.goenv-shim"
 
   run goenv-rehash
-  assert_failure "goenv: cannot rehash: ${GOENV_ROOT}/shims/.goenv-shim exists (rehash in progress or interrupted via SIGKILL)"
+  assert_success ""
+  assert [ -x "${GOENV_ROOT}/shims/go" ]
+}
+
+@test "waits for another rehash to finish and fails once it times out" {
+  mkdir -p "${GOENV_ROOT}/shims/.goenv-rehash.lock"
+  echo "$$" > "${GOENV_ROOT}/shims/.goenv-rehash.lock/pid"
+
+  GOENV_LOCK_TIMEOUT=1 run goenv-rehash
+  assert_failure "goenv: cannot rehash: timed out waiting for another rehash to finish (remove '${GOENV_ROOT}/shims/.goenv-rehash.lock' if no goenv is running)"
+}
+
+@test "takes over the lock of a rehash that was killed" {
+  create_executable "1.11.1" "go"
+  mkdir -p "${GOENV_ROOT}/shims/.goenv-rehash.lock"
+  sh -c 'echo $$' > "${GOENV_ROOT}/shims/.goenv-rehash.lock/pid"
+
+  GOENV_LOCK_TIMEOUT=1 run goenv-rehash
+  assert_success ""
+  assert [ -x "${GOENV_ROOT}/shims/go" ]
+  assert [ ! -e "${GOENV_ROOT}/shims/.goenv-rehash.lock" ]
 }
 
 @test "succeeds in creating executable shims for binaries present in 'GOENV_ROOT/versions/<version>/bin'" {
@@ -150,6 +182,56 @@ OUT
   assert [ -x "${GOENV_ROOT}/shims/go" ]
 }
 
+@test "keeps shims it didn't create when they're listed nowhere" {
+  create_executable "1.11.1" "go"
+  goenv-rehash
+  touch "${GOENV_ROOT}/shims/mytool"
+  chmod +x "${GOENV_ROOT}/shims/mytool"
+  rm "${GOENV_ROOT}/versions/1.11.1/bin/go"
+  create_executable "1.11.1" "gofmt"
+
+  run goenv-rehash
+  assert_success ""
+  assert [ ! -e "${GOENV_ROOT}/shims/go" ]
+  assert [ -x "${GOENV_ROOT}/shims/gofmt" ]
+  assert [ -x "${GOENV_ROOT}/shims/mytool" ]
+
+  run cat "${GOENV_ROOT}/shims/.goenv-manifest"
+  assert_success "gofmt"
+}
+
+@test "reports added and removed shims with --verbose" {
+  create_executable "1.11.1" "go"
+  create_executable "1.11.1" "godoc"
+  goenv-rehash
+  rm "${GOENV_ROOT}/versions/1.11.1/bin/godoc"
+  create_executable "1.11.1" "gofmt"
+
+  run goenv-rehash --verbose
+  assert_success_out <<OUT
+goenv: added shim(s): gofmt
+goenv: removed shim(s): godoc
+OUT
+}
+
+@test "reports shims are up to date with --verbose" {
+  create_executable "1.11.1" "go"
+  goenv-rehash
+
+  run goenv-rehash --verbose
+  assert_success "goenv: shims are up to date"
+}
+
+@test "reports shims created again for another goenv with --verbose" {
+  create_executable "1.11.1" "go"
+  create_executable "1.11.1" "gofmt"
+  goenv-rehash
+  echo "# an older goenv" >> "${GOENV_ROOT}/shims/go"
+
+  run goenv-rehash --verbose
+  assert_success "goenv: created 2 shim(s) again for this goenv"
+}
+
 @test "succeeds in creating shims for binaries present in 'GOENV_ROOT/versions/<version>/bin', even though 'version' contains spaces" {
   create_executable "dirname1 p247" "go"
 
@@ -178,3 +260,356 @@ SH
   IFS=$' \t\n' run goenv-rehash
   assert_success "HELLO=:hello:ugly:world:again"
 }
+
+@test "shims fail immediately when 'GOENV_ROOT' is unavailable" {
+  create_executable "1.11.1" "go"
+  GOENV_SHIMS_DIR="${GOENV_TEST_DIR}/shims" goenv-rehash
+  rm -rf "$GOENV_ROOT"
+
+  run "${GOENV_TEST_DIR}/shims/go" version
+  assert_failure "goenv: GOENV_ROOT '${GOENV_ROOT}' is unavailable (not reachable within 5s)"
+}
+
+@test "shims fall back to 'GOENV_FALLBACK_DIR' when 'GOENV_ROOT' is unavailable" {
+  create_executable "1.11.1" "go"
+  GOENV_SHIMS_DIR="${GOENV_TEST_DIR}/shims" goenv-rehash
+  rm -rf "$GOENV_ROOT"
+  mkdir -p "${GOENV_TEST_DIR}/fallback/bin"
+  cat > "${GOENV_TEST_DIR}/fallback/bin/go" <<SH
+#!/bin/sh
+echo "cached go \$@ GOROOT=\$GOROOT"
+SH
+  chmod +x "${GOENV_TEST_DIR}/fallback/bin/go"
+
+  GOENV_FALLBACK_DIR="${GOENV_TEST_DIR}/fallback" run "${GOENV_TEST_DIR}/shims/go" version
+  assert_success
+  assert_line 0 "goenv: GOENV_ROOT '${GOENV_ROOT}' is unavailable, using the Go cached in '${GOENV_TEST_DIR}/fallback'"
+  assert_line 1 "cached go version GOROOT=${GOENV_TEST_DIR}/fallback"
+}
+
+@test "has completion support" {
+  run goenv-rehash --complete
+  assert_success_out <<OUT
+--versioned-aliases
+--verbose
+OUT
+}
+
+@test "fails and prints usage when unknown arguments are given" {
+  run goenv-rehash --nope
+  assert_failure "Usage: goenv rehash [--versioned-aliases] [--verbose]"
+}
+
+@test "creates versioned alias shims with --versioned-aliases" {
+  create_executable "1.21.13" "go"
+  create_executable "1.22.1" "go"
+  create_executable "1.22.5" "go"
+  create_executable "1.22.5" "gofmt"
+
+  run goenv-rehash --versioned-aliases
+  assert_success ""
+
+  run /bin/ls "${GOENV_ROOT}/shims"
+  assert_success_out <<OUT
+go
+go1.21
+go1.21.13
+go1.22
+go1.22.1
+go1.22.5
+gofmt
+OUT
+}
+
+@test "keeps versioned alias shims when 'GOENV_VERSIONED_ALIASES' is 1" {
+  create_executable "1.22.5" "go"
+  goenv-rehash --versioned-aliases
+
+  GOENV_VERSIONED_ALIASES=1 run goenv-rehash
+  assert_success ""
+  assert [ -x "${GOENV_ROOT}/shims/go1.22.5" ]
+
+  run goenv-rehash
+  assert_success ""
+  assert [ ! -e "${GOENV_ROOT}/shims/go1.22.5" ]
+}
+
+@test "versioned alias shims run the aliased version" {
+  create_executable "1.21.13" "go" <<SH
+#!/bin/sh
+echo "go 1.21.13 \$@"
+SH
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo "go 1.22.5 \$@"
+SH
+  goenv-rehash --versioned-aliases
+
+  GOENV_VERSION=1.22.5 run go1.21 version
+  assert_success "go 1.21.13 version"
+}
+
+@test "versioned alias shims keep their own entries apart from the shims of the selected version" {
+  create_executable "1.21.13" "go" <<SH
+#!/bin/sh
+echo "go 1.21.13 \$@"
+SH
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo "go 1.22.5 \$@"
+SH
+  goenv-rehash --versioned-aliases
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+  echo "1.21.13" > .go-version
+
+  go version
+  "${GOENV_ROOT}/shims/go1.22" version
+  key="${PWD//%/%25}"
+  assert [ -f "${GOENV_ROOT}/shims/.cache/${UID}/go${key//\//%2F}" ]
+  assert [ -f "${GOENV_ROOT}/shims/.cache/${UID}/go1.22${key//\//%2F}" ]
+
+  run bash -x "${GOENV_ROOT}/shims/go1.22" version
+  assert_success
+  assert_line "+ exec -a go ${GOENV_ROOT}/versions/1.22.5/bin/go version"
+  run bash -x "${GOENV_ROOT}/shims/go" version
+  assert_success
+  assert_line "+ exec -a go ${GOENV_ROOT}/versions/1.21.13/bin/go version"
+}
+
+@test "lists versioned alias shims in the manifest" {
+  create_executable "1.22.5" "go"
+  goenv-rehash --versioned-aliases
+
+  run cat "${GOENV_ROOT}/shims/.goenv-manifest"
+  assert_success_out <<OUT
+go
+go1.22 alias
+go1.22.5 alias
+OUT
+  run grep -c BASH_REMATCH "${GOENV_ROOT}/shims/go"
+  assert_failure "0"
+}
+
+@test "shims of executables named like a version run them with the selected version" {
+  create_executable "1.21.13" "go"
+  create_executable "1.22.5" "go"
+  create_executable "1.22.5" "go1.21.13" <<SH
+#!/bin/sh
+echo "wrapper \$@"
+SH
+  goenv-rehash --versioned-aliases
+
+  GOENV_VERSION=1.22.5 run go1.21.13 version
+  assert_success "wrapper version"
+
+  run cat "${GOENV_ROOT}/shims/.goenv-manifest"
+  assert_success_out <<OUT
+go
+go1.21.13
+go1.21 alias
+go1.22 alias
+go1.22.5 alias
+OUT
+}
+
+@test "replaces versioned alias shims of versions that become executables" {
+  create_executable "1.21.13" "go"
+  create_executable "1.22.5" "go"
+  goenv-rehash --versioned-aliases
+  create_executable "1.22.5" "go1.21.13" <<SH
+#!/bin/sh
+echo "wrapper \$@"
+SH
+
+  run goenv-rehash
+  assert_success ""
+
+  GOENV_VERSION=1.22.5 run go1.21.13 version
+  assert_success "wrapper version"
+}
+
+@test "links new shims into the directories of allowlists" {
+  create_executable "1.22.5" "go"
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  printf 'go\ngopls\n' > "${GOENV_TEST_DIR}/project/.goenv-shims"
+  goenv-rehash
+  allowed_dir="$(cd "${GOENV_TEST_DIR}/project" && goenv-shims --allowed)"
+
+  create_executable "1.22.5" "gopls"
+  create_executable "1.22.5" "protoc"
+  run goenv-rehash
+  assert_success ""
+
+  run /bin/ls "$allowed_dir"
+  assert_success_out <<OUT
+go
+gopls
+OUT
+}
+
+@test "removes the shims directories of removed allowlists" {
+  create_executable "1.22.5" "go"
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  echo "go" > "${GOENV_TEST_DIR}/project/.goenv-shims"
+  goenv-rehash
+  allowed_dir="$(cd "${GOENV_TEST_DIR}/project" && goenv-shims --allowed)"
+
+  rm "${GOENV_TEST_DIR}/project/.goenv-shims"
+  run goenv-rehash
+  assert_success ""
+  assert [ ! -d "$allowed_dir" ]
+}
+
+@test "runs executable rehash hooks" {
+  create_executable "1.22.5" "go"
+  create_executable "${GOENV_ROOT}/hooks/rehash" "list" <<SH
+#!$BASH
+ls "\$GOENV_SHIMS_DIR"
+SH
+
+  run goenv-rehash
+
+  assert_success "go"
+}
+
+@test "shims run the command by themselves once goenv exec ran it the same way" {
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo "go 1.22.5 \$@ GOROOT=\$GOROOT"
+SH
+  goenv-rehash
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+  echo "1.22.5" > .go-version
+
+  run go version
+  assert_success "go 1.22.5 version GOROOT=${GOENV_ROOT}/versions/1.22.5"
+  key="${PWD//%/%25}"
+  assert [ -f "${GOENV_ROOT}/shims/.cache/${UID}/go${key//\//%2F}" ]
+
+  run bash -x "${GOENV_ROOT}/shims/go" version
+  assert_success
+  assert_line "+ exec -a go ${GOENV_ROOT}/versions/1.22.5/bin/go version"
+  assert_line "go 1.22.5 version GOROOT=${GOENV_ROOT}/versions/1.22.5"
+}
+
+@test "shims keep only the paths goenv sets, readable by the user only" {
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo "go 1.22.5 \$@ \$GITHUB_TOKEN"
+SH
+  goenv-rehash
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+  echo "1.22.5" > .go-version
+
+  GITHUB_TOKEN=secret run go version
+  assert_success "go 1.22.5 version secret"
+  entry="$(echo "${GOENV_ROOT}/shims/.cache/${UID}/go"*)"
+  run grep -c secret "$entry"
+  assert_failure "0"
+  assert_equal "drwx------" "$(ls -ld "${entry%/*}" | cut -c1-10)"
+  assert_equal "-rw-------" "$(ls -l "$entry" | cut -c1-10)"
+
+  GITHUB_TOKEN=other run go version
+  assert_success "go 1.22.5 version other"
+}
+
+@test "shims run annotated commands through goenv" {
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo "go 1.22.5 \$@"
+SH
+  goenv-rehash
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+  echo "1.22.5" > .go-version
+  go version
+
+  run bash -x "${GOENV_ROOT}/shims/go" env GOROOT
+  assert_success
+  assert_line "+ exec -a go ${GOENV_ROOT}/versions/1.22.5/bin/go env GOROOT"
+
+  GOENV_ANNOTATE=1 run bash -x "${GOENV_ROOT}/shims/go" env GOROOT
+  assert_success
+  assert_line "+ exec $(command -v goenv) exec go env GOROOT"
+}
+
+@test "shims run builds by themselves once goenv found a C compiler" {
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo "go 1.22.5 \$@"
+SH
+  create_executable "${GOENV_TEST_DIR}/bin" "test-cc"
+  goenv-rehash
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+  echo "1.22.5" > .go-version
+  echo 'import "C"' > main.go
+  unset CGO_ENABLED GOENV_DISABLE_CGO_CHECK
+
+  CC=does-not-exist-cc go build 2>/dev/null
+  CC=does-not-exist-cc run bash -x "${GOENV_ROOT}/shims/go" build
+  assert_success
+  assert_line "+ exec $(command -v goenv) exec go build"
+
+  CC=test-cc go build
+  CC=test-cc run bash -x "${GOENV_ROOT}/shims/go" build
+  assert_success
+  assert_line "+ exec -a go ${GOENV_ROOT}/versions/1.22.5/bin/go build"
+}
+
+@test "shims run the command by themselves without starting goenv" {
+  create_executable "1.22.5" "go" "#!/bin/sh"
+  goenv-rehash
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+  echo "1.22.5" > .go-version
+  go version
+
+  run bash -x "${GOENV_ROOT}/shims/go" version
+  assert_success
+  assert_line "+ exec -a go ${GOENV_ROOT}/versions/1.22.5/bin/go version"
+  refute_line "+ exec $(command -v goenv) exec go version"
+
+  GOENV_SHIM_CACHE=0 run bash -x "${GOENV_ROOT}/shims/go" version
+  assert_success
+  assert_line "+ exec $(command -v goenv) exec go version"
+}
+
+@test "shims run goenv exec again when the version file changed" {
+  create_executable "1.21.13" "go" <<SH
+#!/bin/sh
+echo "go 1.21.13 \$@"
+SH
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo "go 1.22.5 \$@"
+SH
+  goenv-rehash
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+  echo "1.22.5" > .go-version
+  run go version
+  assert_success "go 1.22.5 version"
+
+  sleep 1
+  echo "1.21.13" > .go-version
+  run go version
+  assert_success "go 1.21.13 version"
+}
+
+@test "shims always run goenv exec when 'GOENV_SHIM_CACHE' is 0" {
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo "go 1.22.5 \$@"
+SH
+  goenv-rehash
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+
+  GOENV_VERSION=1.22.5 GOENV_SHIM_CACHE=0 run go version
+  assert_success "go 1.22.5 version"
+  assert [ ! -e "${GOENV_ROOT}/shims/.cache" ]
+}
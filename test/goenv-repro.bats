@@ -0,0 +1,125 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5"
+  export GOENV_VERSION="1.22.5"
+  for var in $(compgen -e | grep -E '^(GO|CGO_)' | grep -vxE 'GOENV_(ROOT|VERSION|HOOK_PATH|TEST_DIR)'); do
+    unset "$var"
+  done
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage repro
+  assert_success_out <<OUT
+Usage: goenv repro [-o <file>] [--no-gitignore] -- <command> [arg1 arg2...]
+OUT
+}
+
+@test "fails and prints usage when no command is given" {
+  run goenv-repro
+  assert_failure "Usage: goenv repro [-o <file>] [--no-gitignore] -- <command> [arg1 arg2...]"
+}
+
+@test "fails and prints usage when no output file is given" {
+  run goenv-repro -o
+  assert_failure "Usage: goenv repro [-o <file>] [--no-gitignore] -- <command> [arg1 arg2...]"
+}
+
+@test "captures the Go version, environment and command" {
+  GOFLAGS=-mod=mod CGO_ENABLED=0 GOPATH=/somewhere run goenv-repro -- go test -run 'Foo Bar' ./...
+
+  assert_success
+  assert_line "#   Go:       1.22.5 (set by GOENV_VERSION environment variable)"
+  assert_line "#   command:  go test -run Foo\\ Bar ./..."
+  assert_line "#   arch:     $(uname -m)"
+  assert_line "goenv prefix 1.22.5 >/dev/null 2>&1 || goenv install --skip-existing 1.22.5"
+  assert_line "export GOENV_VERSION=1.22.5"
+  assert_line "export CGO_ENABLED=0"
+  assert_line "export GOFLAGS=-mod=mod"
+  refute_line "export GOPATH=/somewhere"
+  refute_line "export GOENV_ROOT=${GOENV_ROOT}"
+  assert_equal "exec goenv exec go test -run Foo\\ Bar ./..." "${lines[${#lines[@]}-1]}"
+}
+
+@test "leaves credentials and local settings out of the script" {
+  SECRET_TOKEN=hunter2 GOENV_PRESERVE_ENV=SECRET_TOKEN GOENV_PRESERVED_ENV=" SECRET_TOKEN" GOENV_PRESERVED_SECRET_TOKEN=hunter2 \
+    GOPROXY="https://user:pw@proxy.corp,direct" GOSUMDB="sum.corp+abc https://user:pw@sum.corp" \
+    GOENV_ANSWERS="${GOENV_TEST_DIR}/answers" GOENV_NO_INTERACTIVE=1 GOENV_ORG_DEFAULTS=https://corp.example.com/go.env \
+    run goenv-repro -- go build
+
+  assert_success
+  assert_line "export GOPROXY=https://proxy.corp\\,direct"
+  assert_line "export GOSUMDB=sum.corp+abc\\ https://sum.corp"
+  [[ "$output" != *hunter2* ]]
+  [[ "$output" != *user:pw* ]]
+  [[ "$output" != *PRESERVE* ]]
+  [[ "$output" != *GOENV_ANSWERS* ]]
+  [[ "$output" != *GOENV_NO_INTERACTIVE* ]]
+  [[ "$output" != *GOENV_ORG_DEFAULTS* ]]
+}
+
+@test "captures only the variables that affect a build" {
+  GOOS=linux GOARCH=arm64 GOEXPERIMENT=rangefunc CC=clang CGO_CFLAGS=-O2 GOCACHE=/cache GOTELEMETRY=off \
+    GOENV_LOCAL_DIR=/local GOENV_GOCACHE_DIR=/gocache GOENV_GOPATH_PREFIX=/gopath GOENV_MIRROR=https://mirror.corp \
+    GOENV_UID_CACHE_DIR=/uid GOENV_SHELL=bash run goenv-repro -- go build
+
+  assert_success
+  assert_line "export GOOS=linux"
+  assert_line "export GOARCH=arm64"
+  assert_line "export GOEXPERIMENT=rangefunc"
+  assert_line "export CC=clang"
+  assert_line "export CGO_CFLAGS=-O2"
+  refute_line "export GOCACHE=/cache"
+  refute_line "export GOTELEMETRY=off"
+  assert_equal "export GOENV_VERSION=1.22.5" "$(grep '^export GOENV_' <<<"$output")"
+}
+
+@test "leaves out the variables that goenv exec preserves" {
+  mkdir -p "${GOENV_TEST_DIR}/app/cmd"
+  cat > "${GOENV_TEST_DIR}/app/.goenv.toml" <<'TOML'
+env_preserve = ["GOPROXY", "CGO_LDFLAGS"]
+TOML
+  cd "${GOENV_TEST_DIR}/app/cmd"
+
+  GOPROXY=https://token@proxy.corp CGO_LDFLAGS=-L/secret GOFLAGS=-tags=secret GOOS=linux GOENV_PRESERVE_ENV=GOFLAGS \
+    run goenv-repro -- go build
+
+  assert_success
+  assert_line "export GOOS=linux"
+  [[ "$output" != *GOPROXY* ]]
+  [[ "$output" != *CGO_LDFLAGS* ]]
+  [[ "$output" != *GOFLAGS* ]]
+}
+
+@test "does not install the system version" {
+  GOENV_VERSION=system run goenv-repro -- go version
+
+  assert_success
+  assert_line "export GOENV_VERSION=system"
+  refute_line "goenv prefix system >/dev/null 2>&1 || goenv install --skip-existing system"
+}
+
+@test "writes an executable script with --output" {
+  run goenv-repro --output "${GOENV_TEST_DIR}/repro.sh" -- go version
+
+  assert_success "goenv: wrote repro script to ${GOENV_TEST_DIR}/repro.sh"
+  assert [ -x "${GOENV_TEST_DIR}/repro.sh" ]
+  run bash -n "${GOENV_TEST_DIR}/repro.sh"
+  assert_success
+}
+
+@test "adds the script written with --output to .gitignore unless --no-gitignore is given" {
+  mkdir -p "${GOENV_TEST_DIR}/app"
+  cd "${GOENV_TEST_DIR}/app"
+  git init -q .
+
+  run goenv-repro --no-gitignore --output repro.sh -- go version
+  assert_success "goenv: wrote repro script to repro.sh"
+  assert [ ! -e .gitignore ]
+
+  run goenv-repro --output repro.sh -- go version
+  assert_success
+  assert_line 1 "goenv: added '/repro.sh' to $(pwd -P)/.gitignore"
+}
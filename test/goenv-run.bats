@@ -0,0 +1,37 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+@test "has usage instructions" {
+  run goenv-help --usage run
+  assert_success "Usage: goenv run <version> <command> [arg1 arg2...]"
+}
+
+@test "fails and prints usage without a version and a command" {
+  run goenv-run 1.21.5
+  assert_failure "Usage: goenv run <version> <command> [arg1 arg2...]"
+}
+
+@test "completes the installed versions, then the commands" {
+  create_version "1.21.5"
+  create_executable "1.21.5" "Zgo123unique" <<SH
+#!/bin/sh
+SH
+  GOENV_VERSION=1.21.5 goenv-rehash
+
+  run goenv-run --complete
+  assert_success "1.21.5"
+
+  run goenv-run --complete 1.21.5
+  assert_success "Zgo123unique"
+}
+
+@test "runs a command with the given version" {
+  create_executable "1.21.5" "Zgo123unique" <<SH
+#!/bin/sh
+echo "\$GOENV_VERSION \$@"
+SH
+
+  GOENV_VERSION=system run goenv-run 1.21.5 Zgo123unique test ./...
+  assert_success "1.21.5 test ./..."
+}
@@ -0,0 +1,110 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  create_executable "1.22.3" "go" <<SH
+#!/bin/sh
+[ "\$1 \$2 \$3" = "env GOOS GOARCH" ] && printf "linux\\namd64\\n"
+SH
+  export GOENV_VERSION="1.22.3"
+  checksum="$(sha256sum "${GOENV_ROOT}/versions/1.22.3/bin/go" | cut -d' ' -f1)"
+  mkdir -p "$GOENV_TEST_DIR"
+  cd "$GOENV_TEST_DIR"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage sbom
+  assert_success_out <<OUT
+Usage: goenv sbom generate [--format cyclonedx|spdx] [<version>]
+       goenv sbom enhance <file> [<version>]
+OUT
+}
+
+@test "has completion support" {
+  run goenv-sbom --complete
+  assert_success_out <<OUT
+generate
+enhance
+OUT
+
+  run goenv-sbom --complete generate --format
+  assert_success_out <<OUT
+cyclonedx
+spdx
+OUT
+}
+
+@test "generates a CycloneDX SBOM of the selected version" {
+  run goenv-sbom generate
+  assert_success
+  assert_line 1 '  "bomFormat": "CycloneDX",'
+  assert_line '    {"type": "application", "bom-ref": "pkg:golang/golang.org/toolchain@v0.0.1-go1.22.3.linux-amd64", "name": "go", "version": "1.22.3", "description": "Go toolchain for linux-amd64", "licenses": [{"license": {"id": "BSD-3-Clause"}}], "purl": "pkg:golang/golang.org/toolchain@v0.0.1-go1.22.3.linux-amd64", "hashes": [{"alg": "SHA-256", "content": "'"$checksum"'"}]},'
+  assert_line '    {"type": "library", "bom-ref": "pkg:golang/stdlib@1.22.3", "name": "stdlib", "version": "1.22.3", "description": "Go standard library", "licenses": [{"license": {"id": "BSD-3-Clause"}}], "purl": "pkg:golang/stdlib@1.22.3"}'
+}
+
+@test "generates an SPDX SBOM of an installed version" {
+  unset GOENV_VERSION
+  run goenv-sbom generate --format spdx 1.22.3
+  assert_success
+  assert_line 1 '  "spdxVersion": "SPDX-2.3",'
+  assert_line '  "documentDescribes": ["SPDXRef-goenv-go-1.22.3"],'
+  assert_line '    {"spdxElementId": "SPDXRef-goenv-go-1.22.3", "relationshipType": "CONTAINS", "relatedSpdxElement": "SPDXRef-goenv-stdlib-1.22.3"}'
+}
+
+@test "fails for an unknown format or a version that isn't installed" {
+  run goenv-sbom generate --format swid
+  assert_failure "goenv: unknown SBOM format 'swid', expected cyclonedx or spdx"
+
+  run goenv-sbom generate 1.21.0
+  assert_failure "goenv: version '1.21.0' not installed"
+}
+
+@test "adds the toolchain to the formulation of a CycloneDX SBOM" {
+  cat > bom.cdx.json <<JSON
+{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {"component": {"name": "app", "description": "an \\"app\\" [with brackets]"}},
+  "components": []
+}
+JSON
+
+  run goenv-sbom enhance bom.cdx.json
+  assert_success "goenv: added go 1.22.3 to 'bom.cdx.json' as the toolchain it was built with"
+  run sed -n '6,7p' bom.cdx.json
+  assert_success_out <<OUT
+  "formulation": [
+    {"bom-ref": "goenv-go-1.22.3", "components": [{"type": "application", "bom-ref": "pkg:golang/golang.org/toolchain@v0.0.1-go1.22.3.linux-amd64", "name": "go", "version": "1.22.3", "description": "Go toolchain for linux-amd64", "licenses": [{"license": {"id": "BSD-3-Clause"}}], "purl": "pkg:golang/golang.org/toolchain@v0.0.1-go1.22.3.linux-amd64", "hashes": [{"alg": "SHA-256", "content": "${checksum}"}]},
+OUT
+
+  run goenv-sbom enhance bom.cdx.json
+  assert_failure "goenv: 'bom.cdx.json' lists go 1.22.3 already"
+}
+
+@test "adds the toolchain as the build tool of the described package of an SPDX SBOM" {
+  cat > bom.spdx.json <<JSON
+{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "documentDescribes": ["SPDXRef-app"],
+  "packages": [
+    {"SPDXID": "SPDXRef-app", "name": "app"}
+  ]
+}
+JSON
+
+  run goenv-sbom enhance bom.spdx.json
+  assert_success
+  run grep -c '"SPDXID": "SPDXRef-goenv-' bom.spdx.json
+  assert_success "2"
+  run grep -F '"relationshipType": "BUILD_TOOL_OF"' bom.spdx.json
+  assert_success '    {"spdxElementId": "SPDXRef-goenv-go-1.22.3", "relationshipType": "BUILD_TOOL_OF", "relatedSpdxElement": "SPDXRef-app"},'
+}
+
+@test "fails to enhance a file that isn't an SBOM" {
+  echo '{"name": "app"}' > package.json
+
+  run goenv-sbom enhance package.json
+  assert_failure "goenv: 'package.json' is not a CycloneDX or SPDX JSON document"
+}
@@ -0,0 +1,111 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+# Stubs an executable of Git Bash on Windows in PATH.
+stub() {
+  create_executable "${GOENV_TEST_DIR}/bin" "$1"
+}
+
+# Stubs reg.exe, which logs its arguments, and finds the key only once
+# it's been added.
+stub_reg() {
+  cat > "${GOENV_TEST_DIR}/bin/reg.exe" <<SH
+#!/bin/sh
+printf "%s\\n" "reg.exe \$* (MSYS2_ARG_CONV_EXCL=\${MSYS2_ARG_CONV_EXCL})" >> "${GOENV_TEST_DIR}/log"
+[ "\$1" = "query" ] && ! grep -q '^reg.exe add' "${GOENV_TEST_DIR}/log" && exit 1
+exit 0
+SH
+  chmod +x "${GOENV_TEST_DIR}/bin/reg.exe"
+}
+
+setup() {
+  mkdir -p "${GOENV_TEST_DIR}/bin"
+  cat > "${GOENV_TEST_DIR}/bin/uname" <<SH
+#!/bin/sh
+echo MINGW64_NT-10.0-19045
+SH
+  cat > "${GOENV_TEST_DIR}/bin/cygpath" <<SH
+#!/bin/sh
+echo "C:\\\\Program Files\\\\\${2##*/}"
+SH
+  chmod +x "${GOENV_TEST_DIR}/bin/uname" "${GOENV_TEST_DIR}/bin/cygpath"
+  stub pwsh.exe
+  stub_reg
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage setup
+  assert_success "Usage: goenv setup --windows-shell-integration [--remove]"
+}
+
+@test "fails and prints usage without an integration" {
+  run goenv-setup --remove
+  assert_failure "Usage: goenv setup --windows-shell-integration [--remove]"
+}
+
+@test "fails outside of Windows" {
+  rm "${GOENV_TEST_DIR}/bin/uname"
+
+  run goenv-setup --windows-shell-integration
+  assert_failure "goenv: --windows-shell-integration only works on Windows, in Git Bash or MSYS2"
+  assert [ ! -e "${GOENV_TEST_DIR}/log" ]
+}
+
+@test "fails without reg.exe" {
+  rm "${GOENV_TEST_DIR}/bin/reg.exe"
+
+  run goenv-setup --windows-shell-integration
+  assert_failure "goenv: cannot find reg.exe in PATH"
+}
+
+@test "adds an Explorer entry opening PowerShell with goenv initialized" {
+  run goenv-setup --windows-shell-integration
+  assert_success "goenv: added \"Open goenv shell here\" to the Explorer context menu, remove it with 'goenv setup --windows-shell-integration --remove'"
+
+  run cat "${GOENV_TEST_DIR}/log"
+  assert_success_out <<'OUT'
+reg.exe add HKCU\Software\Classes\Directory\Background\shell\goenv /ve /d Open goenv shell here /f (MSYS2_ARG_CONV_EXCL=*)
+reg.exe add HKCU\Software\Classes\Directory\Background\shell\goenv /v Icon /d C:\Program Files\pwsh.exe /f (MSYS2_ARG_CONV_EXCL=*)
+reg.exe add HKCU\Software\Classes\Directory\Background\shell\goenv\command /ve /d "C:\Program Files\pwsh.exe" -NoExit -Command "Set-Location -LiteralPath '%V'; iex ((goenv init - pwsh) -join [char]10)" /f (MSYS2_ARG_CONV_EXCL=*)
+OUT
+}
+
+@test "opens the shell in Windows Terminal when it's installed" {
+  stub wt.exe
+
+  run goenv-setup --windows-shell-integration
+  assert_success
+
+  run grep 'command ' "${GOENV_TEST_DIR}/log"
+  assert_success 'reg.exe add HKCU\Software\Classes\Directory\Background\shell\goenv\command /ve /d "C:\Program Files\wt.exe" -d "%V." "C:\Program Files\pwsh.exe" -NoExit -Command "iex ((goenv init - pwsh) -join [char]10)" /f (MSYS2_ARG_CONV_EXCL=*)'
+}
+
+@test "falls back to Windows PowerShell" {
+  rm "${GOENV_TEST_DIR}/bin/pwsh.exe"
+  stub powershell.exe
+
+  run goenv-setup --windows-shell-integration
+  assert_success
+
+  run grep ' Icon ' "${GOENV_TEST_DIR}/log"
+  assert_success 'reg.exe add HKCU\Software\Classes\Directory\Background\shell\goenv /v Icon /d C:\Program Files\powershell.exe /f (MSYS2_ARG_CONV_EXCL=*)'
+}
+
+@test "removes the Explorer entry" {
+  goenv-setup --windows-shell-integration
+
+  run goenv-setup --windows-shell-integration --remove
+  assert_success "goenv: removed \"Open goenv shell here\" from the Explorer context menu"
+
+  run grep 'reg.exe delete' "${GOENV_TEST_DIR}/log"
+  assert_success 'reg.exe delete HKCU\Software\Classes\Directory\Background\shell\goenv /f (MSYS2_ARG_CONV_EXCL=*)'
+}
+
+@test "removing an Explorer entry that isn't registered succeeds" {
+  run goenv-setup --windows-shell-integration --remove
+  assert_success "goenv: the Explorer entry isn't registered"
+
+  run grep 'reg.exe delete' "${GOENV_TEST_DIR}/log"
+  assert_failure
+}
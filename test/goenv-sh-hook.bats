@@ -0,0 +1,56 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "${GOENV_ROOT}/versions/1.21.5" "${GOENV_ROOT}/versions/1.22.3" "$GOENV_TEST_DIR"
+  echo "1.21.5" > "${GOENV_ROOT}/version"
+  cd "$GOENV_TEST_DIR"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage sh-hook
+  assert_success "Usage: goenv sh-hook cd [<previous-version>]"
+}
+
+@test "fails and prints usage without a hook" {
+  run goenv-sh-hook
+  assert_failure "Usage: goenv sh-hook cd [<previous-version>]"
+}
+
+@test "prints the code that remembers the version selected in the directory" {
+  run goenv-sh-hook cd
+  assert_success "_goenv_hook_cd_version='1.21.5'"
+
+  run goenv-sh-hook cd 1.21.5
+  assert_success "_goenv_hook_cd_version='1.21.5'"
+}
+
+@test "tells which version is used when it's not the previous one" {
+  echo "1.22.3" > .go-version
+
+  run goenv-sh-hook cd 1.21.5
+  assert_success
+  assert_line 0 "_goenv_hook_cd_version='1.22.3'"
+  assert_line 1 "goenv: switched to 1.22.3 via .go-version"
+
+  mkdir -p sub
+  cd sub
+  run goenv-sh-hook cd 1.21.5
+  assert_success
+  assert_line 1 "goenv: switched to 1.22.3 via ${GOENV_TEST_DIR}/.go-version"
+}
+
+@test "tells when the version switched to isn't installed" {
+  echo "1.23.0" > .go-version
+
+  run goenv-sh-hook cd 1.21.5
+  assert_success
+  assert_line 0 "_goenv_hook_cd_version='1.23.0'"
+  assert_line 1 "goenv: switched to 1.23.0 via .go-version, which is not installed, run 'goenv install 1.23.0'"
+}
+
+@test "prints the version for fish" {
+  GOENV_SHELL=fish run goenv-sh-hook cd 1.21.5
+  assert_success "set -g _goenv_hook_cd_version '1.21.5'"
+}
@@ -5,13 +5,16 @@ load test_helper
 @test "has usage instructions for goenv-sh-rehash" {
   run goenv-help --usage sh-rehash
   assert_success_out <<OUT
-Usage: goenv sh-rehash [--only-manage-paths]
+Usage: goenv sh-rehash [--only-manage-paths] [--versioned-aliases] [--verbose]
 OUT
 }
 
-@test "has completion support (but pointless)" {
+@test "completes the arguments of goenv-rehash" {
   run goenv-sh-rehash --complete
-  assert_success ""
+  assert_success_out <<OUT
+--versioned-aliases
+--verbose
+OUT
 }
 
 @test "when current set 'version' is 'system', it does not export GOPATH and GOROOT env variables" {
@@ -442,6 +445,19 @@ set -gx GOPATH "/fake-gopath:/tmp/example/1.12.0"
 OUT
 }
 
+@test "when current set 'version' is not 'system', 'GOENV_DISABLE_GOROOT' is 0, 'GOENV_DISABLE_GOPATH' is 0, 'GOENV_APPEND_GOPATH' is 1, shell is 'pwsh' and 'GOENV_GOPATH_PREFIX' is present, it echoes assignments of 'GOROOT' and 'GOPATH=<set>'" {
+  export GOENV_SHELL=pwsh
+
+  create_version "1.12.0"
+
+  GOENV_VERSION=1.12.0 GOENV_DISABLE_GOROOT=0 GOENV_DISABLE_GOPATH=0 GOENV_APPEND_GOPATH=1 GOPATH=/fake-gopath GOENV_GOPATH_PREFIX=/tmp/example run goenv-sh-rehash
+
+  assert_success_out <<OUT
+\$env:GOROOT = '$(GOENV_VERSION=1.12.0 goenv-prefix)'
+\$env:GOPATH = '/tmp/example/1.12.0:/fake-gopath'
+OUT
+}
+
 @test "creates 'GOENV_ROOT/shims' when it does not exist" {
   assert [ ! -d "${GOENV_ROOT}/shims" ]
   run goenv-sh-rehash
@@ -462,12 +478,14 @@ OUT
   assert_failure "goenv: cannot rehash: ${GOENV_ROOT}/shims isn't writable"
 }
 
-@test "fails when 'GOENV_ROOT/shims/.goenv-shim' is present, meaning rehash in progress or interrupted via SIGKILL" {
+@test "succeeds when a leftover 'GOENV_ROOT/shims/.goenv-shim' is present" {
+  create_executable "1.11.1" "go"
   mkdir -p "${GOENV_ROOT}/shims"
   touch "${GOENV_ROOT}/shims/.goenv-shim"
 
   run goenv-sh-rehash
-  assert_failure "goenv: cannot rehash: ${GOENV_ROOT}/shims/.goenv-shim exists (rehash in progress or interrupted via SIGKILL)"
+  assert_success ""
+  assert [ -x "${GOENV_ROOT}/shims/go" ]
 }
 
 @test "succeeds in creating executable shims for binaries present in 'GOENV_ROOT/versions/<version>/bin'" {
@@ -607,3 +625,29 @@ OUT
   assert_success "go is ${GOENV_ROOT}/shims/go"
 }
 
+@test "passes the arguments of 'goenv rehash' on to goenv-rehash in a shell set up with 'goenv init'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.3/bin"
+  create_executable "${GOENV_ROOT}/versions/1.22.3/bin" "go" "#!/bin/sh"
+  echo "1.22.3" > "${GOENV_ROOT}/version"
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv" <<SH
+#!/bin/sh
+exec "${BATS_TEST_DIRNAME}/../libexec/goenv" "\$@"
+SH
+
+  run bash --norc -c 'eval "$(goenv init - bash)"; goenv rehash --verbose' 2>&1
+  assert_success "goenv: shims are up to date"
+}
+
+@test "creates versioned alias shims with '--versioned-aliases' in a shell set up with 'goenv init'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.3/bin"
+  create_executable "${GOENV_ROOT}/versions/1.22.3/bin" "go" "#!/bin/sh"
+  echo "1.22.3" > "${GOENV_ROOT}/version"
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv" <<SH
+#!/bin/sh
+exec "${BATS_TEST_DIRNAME}/../libexec/goenv" "\$@"
+SH
+
+  run bash --norc -c 'eval "$(goenv init - bash)"; goenv rehash --versioned-aliases'
+  assert_success
+  assert [ -x "${GOENV_ROOT}/shims/go1.22.3" ]
+}
@@ -30,22 +30,57 @@ OUT
 
 @test "prints unset variable when '--unset' is given in arguments and shell is 'bash'" {
   GOENV_SHELL=bash run goenv-sh-shell --unset
-  assert_success 'unset GOENV_VERSION'
+  assert_success
+  assert_line 0 'unset GOENV_VERSION'
+  assert_line 1 "goenv: now using system (set by ${GOENV_ROOT}/version)"
 }
 
 @test "prints unset variable when '--unset' is given in arguments and shell is 'zsh'" {
   GOENV_SHELL=zsh run goenv-sh-shell --unset
-  assert_success 'unset GOENV_VERSION'
+  assert_success
+  assert_line 0 'unset GOENV_VERSION'
+  assert_line 1 "goenv: now using system (set by ${GOENV_ROOT}/version)"
 }
 
 @test "prints unset variable when '--unset' is given in arguments and shell is 'ksh'" {
   GOENV_SHELL=ksh run goenv-sh-shell --unset
-  assert_success 'unset GOENV_VERSION'
+  assert_success
+  assert_line 0 'unset GOENV_VERSION'
+  assert_line 1 "goenv: now using system (set by ${GOENV_ROOT}/version)"
 }
 
 @test "prints unset variable when '--unset' is given in arguments and shell is 'fish'" {
   GOENV_SHELL=fish run goenv-sh-shell --unset
-  assert_success 'set -e GOENV_VERSION'
+  assert_success
+  assert_line 0 'set -e GOENV_VERSION'
+  assert_line 1 "goenv: now using system (set by ${GOENV_ROOT}/version)"
+}
+
+@test "prints unset variable when '--unset' is given in arguments and shell is 'pwsh'" {
+  GOENV_SHELL=pwsh run goenv-sh-shell --unset
+  assert_success
+  assert_line 0 'Remove-Item Env:GOENV_VERSION -ErrorAction SilentlyContinue'
+  assert_line 1 "goenv: now using system (set by ${GOENV_ROOT}/version)"
+}
+
+@test "prints unset variable when '--unset' is given in arguments and shell is 'cmd'" {
+  GOENV_SHELL=cmd.exe run goenv-sh-shell --unset
+  assert_success
+  assert_line 0 "set GOENV_VERSION="
+  assert_line 1 "goenv: now using system (set by ${GOENV_ROOT}/version)"
+}
+
+@test "shows the version used after '--unset'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5"
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+  echo "1.22.5" > .go-version
+
+  GOENV_SHELL=bash GOENV_VERSION=1.21.0 run goenv-sh-shell --unset
+
+  assert_success
+  assert_line 0 "unset GOENV_VERSION"
+  assert_line 1 "goenv: now using 1.22.5 (set by ${GOENV_TEST_DIR}/project/.go-version)"
 }
 
 @test "changes 'GOENV_VERSION' environment variable to specified shell version argument if it's installed in GOENV_ROOT/versions/<version> and shell is 'bash'" {
@@ -80,6 +115,22 @@ OUT
   assert_success 'set -gx GOENV_VERSION "1.2.3"'
 }
 
+@test "changes 'GOENV_VERSION' environment variable to specified shell version argument if it's installed in GOENV_ROOT/versions/<version> and shell is 'pwsh'" {
+  mkdir -p ${GOENV_ROOT}/versions/1.2.3
+
+  GOENV_SHELL=pwsh run goenv-sh-shell 1.2.3
+
+  assert_success "\$env:GOENV_VERSION = '1.2.3'"
+}
+
+@test "changes 'GOENV_VERSION' environment variable to specified shell version argument if it's installed in GOENV_ROOT/versions/<version> and shell is 'cmd'" {
+  mkdir -p ${GOENV_ROOT}/versions/1.2.3
+
+  GOENV_SHELL=cmd run goenv-sh-shell 1.2.3
+
+  assert_success "set GOENV_VERSION=1.2.3"
+}
+
 @test "fails changing 'GOENV_VERSION' environment variable to specified shell version argument if version does not exist in GOENV_ROOT/versions/<version>" {
   GOENV_SHELL=bash run goenv-sh-shell 1.2.3
 
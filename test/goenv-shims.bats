@@ -6,6 +6,7 @@ load test_helper
   run goenv-help --usage shims
   assert_success_out <<OUT
 Usage: goenv shims [--short]
+       goenv shims --allowed [<allowlist>]
 OUT
 }
 
@@ -13,6 +14,7 @@ OUT
   run goenv-shims --complete
   assert_success_out <<OUT
 --short
+--allowed
 OUT
 }
 
@@ -52,3 +54,56 @@ godoc
 gofmt
 OUT
 }
+
+@test "prints found shims from 'GOENV_SHIMS_DIR' when it is set" {
+  export GOENV_SHIMS_DIR="${HOME}/.local/share/goenv/shims"
+  mkdir -p "${GOENV_SHIMS_DIR}"
+  touch "${GOENV_SHIMS_DIR}/go"
+
+  run goenv-shims
+
+  assert_success "${GOENV_SHIMS_DIR}/go"
+}
+
+@test "fails without output for '--allowed' when there is no allowlist" {
+  mkdir -p "${GOENV_ROOT}/shims"
+  touch "${GOENV_ROOT}/shims/go"
+
+  run goenv-shims --allowed
+
+  assert_failure ""
+}
+
+@test "links only the shims named by '.goenv-shims' for '--allowed'" {
+  mkdir -p "${GOENV_ROOT}/shims" "${GOENV_TEST_DIR}/project/pkg"
+  touch "${GOENV_ROOT}/shims/go" "${GOENV_ROOT}/shims/gofmt" "${GOENV_ROOT}/shims/gopls" "${GOENV_ROOT}/shims/protoc"
+  cat > "${GOENV_TEST_DIR}/project/.goenv-shims" <<CONFIG
+# Only Go itself
+go*
+gopls
+CONFIG
+  cd "${GOENV_TEST_DIR}/project/pkg"
+
+  run goenv-shims --allowed
+
+  assert_success
+  [[ "$output" == "${GOENV_ROOT}/shims-allowed/"* ]]
+  run /bin/ls "$output"
+  assert_success_out <<OUT
+go
+gofmt
+gopls
+OUT
+}
+
+@test "uses 'GOENV_ROOT/allowed-shims' for '--allowed' outside projects with an allowlist" {
+  mkdir -p "${GOENV_ROOT}/shims"
+  touch "${GOENV_ROOT}/shims/go" "${GOENV_ROOT}/shims/protoc"
+  echo "go" > "${GOENV_ROOT}/allowed-shims"
+
+  run goenv-shims --allowed
+
+  assert_success
+  run /bin/ls "$output"
+  assert_success "go"
+}
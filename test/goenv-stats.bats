@@ -0,0 +1,162 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+@test "has usage instructions" {
+  run goenv-help --usage stats
+  assert_success_out <<OUT
+Usage: goenv stats [--days <N>]
+       goenv stats on|off
+OUT
+}
+
+@test "fails and prints usage with an invalid number of days" {
+  run goenv-stats --days soon
+  assert_failure
+  assert_line 0 "Usage: goenv stats [--days <N>]"
+}
+
+@test "fails when usage isn't recorded" {
+  run goenv-stats
+  assert_failure "goenv: usage isn't recorded, turn it on with 'goenv stats on'"
+}
+
+@test "doesn't record runs unless turned on" {
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo go
+SH
+
+  GOENV_VERSION=1.22.5 run goenv-exec go
+  assert_success "go"
+  assert [ ! -e "${GOENV_ROOT}/usage.log" ]
+}
+
+@test "records runs of goenv exec once turned on" {
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo go
+SH
+
+  run goenv-stats on
+  assert_success "goenv: recording usage in ${GOENV_ROOT}/usage.log"
+
+  GOENV_VERSION=1.22.5 run goenv-exec go version
+  assert_success "go"
+  run awk '{ print $2, $3 }' "${GOENV_ROOT}/usage.log"
+  assert_success "1.22.5 go"
+}
+
+@test "records runs of shims that run the command by themselves" {
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo go
+SH
+  goenv-rehash
+  goenv-stats on
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+  echo "1.22.5" > .go-version
+
+  run go version
+  assert_success "go"
+  run go version
+  assert_success "go"
+
+  run awk '{ print $2, $3 }' "${GOENV_ROOT}/usage.log"
+  assert_success_out <<OUT
+1.22.5 go
+1.22.5 go
+OUT
+}
+
+@test "stops recording and removes the usage log when turned off" {
+  goenv-stats on
+
+  run goenv-stats off
+  assert_success "goenv: stopped recording usage"
+  assert [ ! -e "${GOENV_ROOT}/usage.log" ]
+}
+
+@test "shows the installed versions least recently used first" {
+  create_executable "1.21.13" "go"
+  create_executable "1.22.5" "go"
+  create_executable "1.23.1" "go"
+  now="$(date +%s)"
+  cat > "${GOENV_ROOT}/usage.log" <<LOG
+$((now - 40 * 86400)) 1.22.5 go
+$((now - 3 * 86400)) 1.23.1 go
+$((now - 2 * 86400)) 1.22.5 gofmt
+$((now - 86400)) 1.23.1 go
+$((now - 86400)) 1.20.14 go
+LOG
+
+  run goenv-stats
+  assert_success
+  assert_line 0 "version last-used  runs size"
+  [[ "${lines[1]}" == "1.21.13 never      0    "*"KB" ]]
+  [[ "${lines[2]}" == "1.22.5  $(date -u -d "@$((now - 2 * 86400))" +%Y-%m-%d) 1    "*"KB" ]]
+  [[ "${lines[3]}" == "1.23.1  $(date -u -d "@$((now - 86400))" +%Y-%m-%d) 2    "*"KB" ]]
+  assert_line 4 "Runs are counted over the last 30 days."
+  assert_equal 5 "${#lines[@]}"
+}
+
+@test "counts the runs of the given number of days" {
+  create_executable "1.22.5" "go"
+  now="$(date +%s)"
+  cat > "${GOENV_ROOT}/usage.log" <<LOG
+$((now - 40 * 86400)) 1.22.5 go
+$((now - 2 * 86400)) 1.22.5 go
+LOG
+
+  run goenv-stats --days 90
+  assert_success
+  [[ "${lines[1]}" == "1.22.5  "*" 2    "*"KB" ]]
+  assert_line 2 "Runs are counted over the last 90 days."
+}
+
+@test "keeps only recent runs and the last run of each version once the usage log grows" {
+  create_executable "1.21.13" "go"
+  create_executable "1.22.5" "go"
+  now="$(date +%s)"
+  old="$((now - 60 * 86400))"
+  for i in $(seq 1000); do
+    echo "${old} 1.22.5 go"
+  done > "${GOENV_ROOT}/usage.log"
+  echo "$((old + 1)) 1.21.13 go" >> "${GOENV_ROOT}/usage.log"
+  echo "${now} 1.22.5 go" >> "${GOENV_ROOT}/usage.log"
+
+  run goenv-stats
+  assert_success
+  run cat "${GOENV_ROOT}/usage.log"
+  assert_success_out <<OUT
+$((old + 1)) 1.21.13 go
+${now} 1.22.5 go
+OUT
+}
+
+@test "runs commands without an error when the usage log isn't writable" {
+  if [ "$(whoami)" = "root" ]; then
+    skip "running as root. permissions won't matter."
+  fi
+  create_executable "1.22.5" "go" <<SH
+#!/bin/sh
+echo go
+SH
+  goenv-rehash
+  goenv-stats on
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+  echo "1.22.5" > .go-version
+  chmod 0444 "${GOENV_ROOT}/usage.log"
+
+  chmod 0555 "$GOENV_ROOT"
+  run go version
+  chmod 0755 "$GOENV_ROOT"
+  assert_success "go"
+
+  chmod 0555 "$GOENV_ROOT"
+  run go version
+  chmod 0755 "$GOENV_ROOT"
+  assert_success "go"
+}
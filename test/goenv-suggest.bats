@@ -0,0 +1,95 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "${GOENV_TEST_DIR}/project"
+  cd "${GOENV_TEST_DIR}/project"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage suggest
+  assert_success_out <<OUT
+Usage: goenv suggest [--apply]
+OUT
+}
+
+@test "has completion support" {
+  run goenv-suggest --complete
+  assert_success "--apply"
+}
+
+@test "fails and prints usage when unknown arguments are given" {
+  run goenv-suggest --nope
+  assert_failure "Usage: goenv suggest [--apply]"
+}
+
+@test "fails when no versions are installed or known" {
+  run goenv-suggest
+  assert_failure "goenv: no Go versions are installed or known, run 'goenv install --list'"
+}
+
+@test "suggests the newest patch release of the version required by 'go.mod'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.21.3" "${GOENV_ROOT}/versions/1.22.1" "${GOENV_ROOT}/versions/1.22.5" "${GOENV_ROOT}/versions/1.23.0"
+  printf 'module example.com/app\n\ngo 1.22.2\n' > go.mod
+
+  run goenv-suggest
+  assert_success_out <<OUT
+Suggested version: 1.22.5
+  - go.mod requires go 1.22.2
+  - 1.22.5 is the newest patch release of go 1.22 (installed)
+  - go 1.22 is supported and receives security fixes
+Run 'goenv suggest --apply' to write it to .go-version
+OUT
+}
+
+@test "considers the Go version used by CI configuration" {
+  mkdir -p "${GOENV_ROOT}/versions/1.21.3" "${GOENV_ROOT}/versions/1.22.1" "${GOENV_ROOT}/versions/1.23.0"
+  mkdir -p .github/workflows
+  cat > .github/workflows/ci.yml <<YML
+jobs:
+  test:
+    steps:
+      - uses: actions/setup-go@v5
+        with:
+          go-version: '1.21'
+YML
+
+  run goenv-suggest
+  assert_success_out <<OUT
+Suggested version: 1.21.3
+  - .github/workflows/ci.yml uses go 1.21
+  - 1.21.3 is the newest patch release of go 1.21 (installed)
+  - go 1.21 is no longer supported and doesn't receive security fixes, consider upgrading to go 1.23
+Run 'goenv suggest --apply' to write it to .go-version
+OUT
+}
+
+@test "suggests the newest version when nothing is required" {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.1" "${GOENV_ROOT}/versions/1.23.0"
+
+  run goenv-suggest
+  assert_success
+  assert_line 0 "Suggested version: 1.23.0"
+  assert_line 1 "  - no Go version is required by go.mod or CI configuration"
+}
+
+@test "fails when no release satisfies 'go.mod'" {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.1"
+  printf 'module example.com/app\n\ngo 1.22.4\n' > go.mod
+
+  run goenv-suggest
+  assert_failure "goenv: no Go release satisfying 1.22.4 is installed or known"
+}
+
+@test "writes the suggested version to '.go-version' with --apply" {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5"
+  printf 'module example.com/app\n\ngo 1.22.2\n' > go.mod
+  mkdir -p cmd/app
+  cd cmd/app
+
+  run goenv-suggest --apply
+  assert_success
+  assert_line 4 "Wrote 1.22.5 to ${GOENV_TEST_DIR}/project/.go-version"
+  assert_equal "1.22.5" "$(cat "${GOENV_TEST_DIR}/project/.go-version")"
+}
@@ -0,0 +1,78 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+# Creates a Go version with a fake `go telemetry' that keeps the mode in
+# a file shared by all versions, like Go does.
+create_go() {
+  create_executable "$1" "go" <<SH
+#!/bin/sh
+[ "\$1" = "telemetry" ] || exit 1
+mode_file="${HOME}/.config/go/telemetry/mode"
+if [ -n "\$2" ]; then
+  mkdir -p "\${mode_file%/*}" && echo "\$2" > "\$mode_file"
+else
+  cat "\$mode_file" 2>/dev/null || echo local
+fi
+SH
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage telemetry
+  assert_success_out <<OUT
+Usage: goenv telemetry [on|off|local]
+       goenv telemetry --apply <version>
+OUT
+}
+
+@test "fails and prints usage with an unknown mode" {
+  run goenv-telemetry maybe
+  assert_failure
+  assert_line 0 "Usage: goenv telemetry [on|off|local]"
+}
+
+@test "fails to show the mode without a version providing telemetry" {
+  create_executable "1.22.5" "go"
+
+  run goenv-telemetry
+  assert_failure "goenv: no installed Go version provides telemetry (1.23 or later)"
+}
+
+@test "shows the mode of each version providing telemetry" {
+  create_executable "1.22.5" "go"
+  create_go "1.23.1"
+  create_go "1.24.0"
+
+  run goenv-telemetry
+  assert_success_out <<OUT
+1.23.1: local
+1.24.0: local
+OUT
+}
+
+@test "sets the mode for all versions" {
+  create_go "1.23.1"
+  create_go "1.24.0"
+
+  run goenv-telemetry off
+  assert_success "goenv: Go telemetry is 'off' for all installed versions"
+  assert_equal "off" "$(cat "${GOENV_ROOT}/telemetry")"
+
+  run goenv-telemetry
+  assert_success_out <<OUT
+1.23.1: off
+1.24.0: off
+OUT
+}
+
+@test "remembers the mode until a version providing telemetry is installed" {
+  run goenv-telemetry off
+  assert_success "goenv: no installed Go version provides telemetry yet, 'off' will be set when Go 1.23 or later is installed"
+
+  create_go "1.24.0"
+  run goenv-telemetry --apply 1.24.0
+  assert_success ""
+
+  run goenv-telemetry
+  assert_success "1.24.0: off"
+}
@@ -0,0 +1,213 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "${GOENV_TEST_DIR}/app"
+  cd "${GOENV_TEST_DIR}/app"
+  cat > go.mod <<MOD
+module example.com/app
+
+go 1.24
+
+tool golang.org/x/tools/cmd/stringer
+
+tool (
+	github.com/99designs/gqlgen/v2 // codegen
+	honnef.co/go/tools/cmd/staticcheck
+)
+MOD
+  echo "1.24.0" > .go-version
+  create_version "1.24.0"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage tools
+  assert_success_out <<OUT
+Usage: goenv tools list --from-gomod
+       goenv tools sync --from-gomod [<tool>...]
+       goenv tools sync --from-version <version> [<tool>...]
+       goenv tools install
+       goenv tools verify
+OUT
+}
+
+@test "completes the tools declared in go.mod for 'sync'" {
+  run goenv-tools --complete sync
+  assert_success_out <<OUT
+--from-gomod
+--from-version
+stringer
+gqlgen
+staticcheck
+OUT
+}
+
+@test "fails and prints usage without '--from-gomod'" {
+  run goenv-tools sync
+  assert_failure
+  assert_line 0 "Usage: goenv tools list --from-gomod"
+}
+
+@test "fails when there's no go.mod" {
+  rm go.mod
+  run goenv-tools list --from-gomod
+  assert_failure "goenv: no go.mod found in '${GOENV_TEST_DIR}/app' or its parent directories"
+}
+
+@test "fails for the system version" {
+  rm .go-version
+  run goenv-tools list --from-gomod
+  assert_failure "goenv: tools can't be installed for the system version, select a version with 'goenv local'"
+}
+
+@test "lists the tools declared in go.mod and whether they're installed" {
+  create_executable "${HOME}/go/1.24.0/bin" "stringer" "#!/bin/sh"
+  create_executable "${HOME}/go/1.24.0/bin" "gqlgen" "#!/bin/sh"
+  touch -d "1 hour ago" "${HOME}/go/1.24.0/bin/gqlgen" 2>/dev/null ||
+    touch -t "$(date -v-1H +%Y%m%d%H%M)" "${HOME}/go/1.24.0/bin/gqlgen"
+  touch go.mod "${HOME}/go/1.24.0/bin/stringer"
+
+  run goenv-tools list --from-gomod
+  assert_success_out <<OUT
+stringer    golang.org/x/tools/cmd/stringer    installed
+gqlgen      github.com/99designs/gqlgen/v2     stale
+staticcheck honnef.co/go/tools/cmd/staticcheck missing
+OUT
+}
+
+@test "installs the tools declared in go.mod with 'go install' of the selected version" {
+  create_executable "1.24.0" "go" <<SH
+#!/bin/sh
+echo "go \$@ into \$GOBIN from \${PWD}"
+SH
+  mkdir sub
+  cd sub
+
+  run goenv-tools sync --from-gomod
+  assert_success_out <<OUT
+Installing golang.org/x/tools/cmd/stringer for go 1.24.0...
+go install golang.org/x/tools/cmd/stringer into ${HOME}/go/1.24.0/bin from ${GOENV_TEST_DIR}/app
+Installing github.com/99designs/gqlgen/v2 for go 1.24.0...
+go install github.com/99designs/gqlgen/v2 into ${HOME}/go/1.24.0/bin from ${GOENV_TEST_DIR}/app
+Installing honnef.co/go/tools/cmd/staticcheck for go 1.24.0...
+go install honnef.co/go/tools/cmd/staticcheck into ${HOME}/go/1.24.0/bin from ${GOENV_TEST_DIR}/app
+OUT
+}
+
+@test "installs only the given tools by name or package" {
+  create_executable "1.24.0" "go" <<SH
+#!/bin/sh
+echo "go \$@"
+SH
+
+  run goenv-tools sync --from-gomod staticcheck golang.org/x/tools/cmd/stringer
+  assert_success_out <<OUT
+Installing honnef.co/go/tools/cmd/staticcheck for go 1.24.0...
+go install honnef.co/go/tools/cmd/staticcheck
+Installing golang.org/x/tools/cmd/stringer for go 1.24.0...
+go install golang.org/x/tools/cmd/stringer
+OUT
+}
+
+@test "fails for a tool that go.mod doesn't declare" {
+  run goenv-tools sync --from-gomod gopls
+  assert_failure "goenv: ${GOENV_TEST_DIR}/app/go.mod declares no tool 'gopls'"
+}
+
+@test "fails when there's no .goenv-tools" {
+  run goenv-tools verify
+  assert_failure "goenv: no .goenv-tools found in '${GOENV_TEST_DIR}/app' or its parent directories"
+}
+
+@test "installs the tools listed in .goenv-tools" {
+  create_executable "1.24.0" "go" <<SH
+#!/bin/sh
+echo "go \$@ into \$GOBIN"
+SH
+  cat > .goenv-tools <<MANIFEST
+# Linters
+github.com/golangci/golangci-lint/cmd/golangci-lint@v1.59.0
+golang.org/x/tools/gopls
+MANIFEST
+
+  run goenv-tools install
+  assert_success_out <<OUT
+Installing github.com/golangci/golangci-lint/cmd/golangci-lint@v1.59.0 for go 1.24.0...
+go install github.com/golangci/golangci-lint/cmd/golangci-lint@v1.59.0 into ${HOME}/go/1.24.0/bin
+Installing golang.org/x/tools/gopls@latest for go 1.24.0...
+go install golang.org/x/tools/gopls@latest into ${HOME}/go/1.24.0/bin
+OUT
+}
+
+@test "verifies the versions of the tools listed in .goenv-tools" {
+  create_executable "1.24.0" "go" <<SH
+#!/bin/sh
+printf '%s: go1.24.0\\n\\tpath\\tx\\n\\tmod\\tx\\tv1.58.0\\th1:x\\n' "\$3"
+SH
+  create_executable "${HOME}/go/1.24.0/bin" "golangci-lint" <<SH
+#!/bin/sh
+SH
+  create_executable "${HOME}/go/1.24.0/bin" "gopls" <<SH
+#!/bin/sh
+SH
+  cat > .goenv-tools <<MANIFEST
+github.com/golangci/golangci-lint/cmd/golangci-lint@v1.59.0
+golang.org/x/tools/gopls@latest
+honnef.co/go/tools/cmd/staticcheck@v0.4.7
+MANIFEST
+
+  run goenv-tools verify
+  assert_failure
+  assert_output <<OUT
+golangci-lint github.com/golangci/golangci-lint/cmd/golangci-lint@v1.59.0 drifted v1.58.0
+gopls         golang.org/x/tools/gopls@latest                             ok
+staticcheck   honnef.co/go/tools/cmd/staticcheck@v0.4.7                   missing
+goenv: installed tools differ from ${GOENV_TEST_DIR}/app/.goenv-tools, run 'goenv tools install'
+OUT
+
+  echo "golang.org/x/tools/gopls" > .goenv-tools
+  run goenv-tools verify
+  assert_success "gopls golang.org/x/tools/gopls@latest ok"
+}
+
+@test "rebuilds the tools of another version from their module information" {
+  create_version "1.22.0"
+  create_executable "1.24.0" "go" <<SH
+#!/bin/sh
+case "\$1 \$2" in
+"version -m")
+  [ "\${3##*/}" = "stringer" ] || exit 1
+  printf '%s: go1.22.0\\n\\tpath\\tgolang.org/x/tools/cmd/stringer\\n\\tmod\\tgolang.org/x/tools\\tv0.20.0\\th1:x\\n' "\$3"
+  ;;
+*)
+  echo "go \$@ into \$GOBIN"
+  ;;
+esac
+SH
+  create_executable "${HOME}/go/1.22.0/bin" "stringer" <<SH
+#!/bin/sh
+SH
+  create_executable "${HOME}/go/1.22.0/bin" "local-tool" <<SH
+#!/bin/sh
+SH
+
+  run goenv-tools sync --from-version 1.22.0
+  assert_success_out <<OUT
+Copying local-tool from go 1.22.0, it has no module information to rebuild it from...
+Rebuilding golang.org/x/tools/cmd/stringer@v0.20.0 for go 1.24.0...
+go install golang.org/x/tools/cmd/stringer@v0.20.0 into ${HOME}/go/1.24.0/bin
+OUT
+  assert [ -x "${HOME}/go/1.24.0/bin/local-tool" ]
+}
+
+@test "fails to sync the tools of another version that doesn't have them" {
+  run goenv-tools sync --from-version 1.22.0
+  assert_failure "goenv: go 1.22.0 has no tools in '${HOME}/go/1.22.0/bin'"
+
+  create_executable "${HOME}/go/1.22.0/bin" "stringer" <<SH
+#!/bin/sh
+SH
+  run goenv-tools sync --from-version 1.22.0 gopls
+  assert_failure "goenv: go 1.22.0 has no tool 'gopls'"
+}
@@ -0,0 +1,312 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+# Copies goenv into a directory, to tell how it was installed by where
+# it is.
+install_goenv() {
+  goenv_dir="$1"
+  mkdir -p "$goenv_dir"
+  cp -R "${BATS_TEST_DIRNAME}/../libexec" "${BATS_TEST_DIRNAME}/../bin" "$goenv_dir"
+  echo "2.2.0" > "${goenv_dir}/APP_VERSION"
+}
+
+# Publishes a release archive of goenv with its SHA256SUMS, and lists
+# them as the assets of the latest release.
+release_archive() {
+  local release_dir="${GOENV_TEST_DIR}/release"
+  mkdir -p "${release_dir}/goenv-${1}"
+  cp -R "${BATS_TEST_DIRNAME}/../libexec" "${BATS_TEST_DIRNAME}/../bin" "${release_dir}/goenv-${1}"
+  echo "$1" > "${release_dir}/goenv-${1}/APP_VERSION"
+  tar -czf "${release_dir}/goenv-${1}.tar.gz" -C "$release_dir" "goenv-${1}"
+  (cd "$release_dir" && sha256sum "goenv-${1}.tar.gz" > SHA256SUMS)
+  cat > "${GOENV_TEST_DIR}/latest.json" <<JSON
+{
+  "tag_name": "v${1}",
+  "assets": [
+    {
+      "name": "goenv-${1}.tar.gz",
+      "browser_download_url": "${release_dir}/goenv-${1}.tar.gz"
+    },
+    {
+      "name": "SHA256SUMS",
+      "browser_download_url": "${release_dir}/SHA256SUMS"
+    }
+  ]
+}
+JSON
+}
+
+# Stubs gh, which verifies attestations with the given exit status.
+stub_gh() {
+  cat > "${GOENV_TEST_DIR}/bin/gh" <<SH
+#!/bin/sh
+echo "gh \$*" >> "${GOENV_TEST_DIR}/log"
+exit ${1}
+SH
+  chmod +x "${GOENV_TEST_DIR}/bin/gh"
+}
+
+setup() {
+  cat > "${GOENV_TEST_DIR}/latest.json" <<JSON
+{
+  "url": "https://api.github.com/repos/go-nv/goenv/releases/1",
+  "tag_name": "v2.3.0",
+  "name": "v2.3.0"
+}
+JSON
+  export GOENV_UPDATE_URL="${GOENV_TEST_DIR}/latest.json"
+  mkdir -p "${GOENV_TEST_DIR}/bin"
+  export PATH="${GOENV_TEST_DIR}/bin:${PATH}"
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage update
+  assert_success "Usage: goenv update [--check] [--no-verify]"
+}
+
+@test "fails when the latest release cannot be looked up" {
+  install_goenv "${GOENV_TEST_DIR}/goenv"
+  GOENV_UPDATE_URL="${GOENV_TEST_DIR}/nope.json" run "${goenv_dir}/libexec/goenv-update" --check
+  assert_failure "goenv: cannot look up the latest release at '${GOENV_TEST_DIR}/nope.json'"
+}
+
+@test "reports the latest release is installed" {
+  install_goenv "${GOENV_TEST_DIR}/goenv"
+  echo "2.3.0" > "${goenv_dir}/APP_VERSION"
+
+  run "${goenv_dir}/libexec/goenv-update"
+  assert_success "goenv: 2.3.0 is the latest release"
+}
+
+@test "reports how goenv was installed and the latest release with --check" {
+  install_goenv "${GOENV_TEST_DIR}/Cellar/goenv/2.2.0"
+
+  run "${goenv_dir}/libexec/goenv-update" --check
+  assert_success "goenv: 2.2.0 is installed with homebrew, 2.3.0 is available"
+}
+
+@test "upgrades goenv installed with Homebrew" {
+  install_goenv "${GOENV_TEST_DIR}/Cellar/goenv/2.2.0"
+  cat > "${GOENV_TEST_DIR}/bin/brew" <<SH
+#!/bin/sh
+echo "brew \$*" >> "${GOENV_TEST_DIR}/log"
+[ "\$1" != "--prefix" ] || echo "${goenv_dir}"
+SH
+  chmod +x "${GOENV_TEST_DIR}/bin/brew"
+
+  run "${goenv_dir}/libexec/goenv-update"
+  assert_success "goenv: updated from 2.2.0 to 2.3.0"
+  run cat "${GOENV_TEST_DIR}/log"
+  assert_success_out <<OUT
+brew upgrade goenv
+brew --prefix goenv
+OUT
+}
+
+@test "updates goenv installed with Scoop" {
+  install_goenv "${GOENV_TEST_DIR}/scoop/apps/goenv/2.2.0"
+  cat > "${GOENV_TEST_DIR}/bin/scoop" <<SH
+#!/bin/sh
+echo "scoop \$*" >> "${GOENV_TEST_DIR}/log"
+[ "\$1" != "prefix" ] || echo "${goenv_dir}"
+SH
+  chmod +x "${GOENV_TEST_DIR}/bin/scoop"
+
+  run "${goenv_dir}/libexec/goenv-update"
+  assert_success "goenv: updated from 2.2.0 to 2.3.0"
+  run cat "${GOENV_TEST_DIR}/log"
+  assert_success_out <<OUT
+scoop update goenv
+scoop prefix goenv
+OUT
+}
+
+@test "upgrades goenv installed with Chocolatey" {
+  install_goenv "${GOENV_TEST_DIR}/Chocolatey/lib/goenv/tools"
+  cat > "${GOENV_TEST_DIR}/bin/choco" <<SH
+#!/bin/sh
+echo "choco \$*" >> "${GOENV_TEST_DIR}/log"
+SH
+  chmod +x "${GOENV_TEST_DIR}/bin/choco"
+
+  run "${goenv_dir}/libexec/goenv-update" --check
+  assert_success "goenv: 2.2.0 is installed with chocolatey, 2.3.0 is available"
+
+  run "${goenv_dir}/libexec/goenv-update"
+  assert_success "goenv: updated from 2.2.0 to 2.3.0"
+  run cat "${GOENV_TEST_DIR}/log"
+  assert_success "choco upgrade goenv -y"
+}
+
+@test "fast-forwards the checked out branch of a git checkout to its verified upstream" {
+  install_goenv "${GOENV_TEST_DIR}/goenv"
+  mkdir "${goenv_dir}/.git"
+  cat > "${GOENV_TEST_DIR}/bin/git" <<SH
+#!/bin/sh
+echo "git \$*" >> "${GOENV_TEST_DIR}/log"
+[ "\$3" != "merge" ] || echo "2.3.1" > "${goenv_dir}/APP_VERSION"
+SH
+  chmod +x "${GOENV_TEST_DIR}/bin/git"
+
+  run "${goenv_dir}/libexec/goenv-update"
+  assert_success "goenv: updated from 2.2.0 to 2.3.1"
+  run cat "${GOENV_TEST_DIR}/log"
+  assert_success_out <<OUT
+git -C ${goenv_dir} status --porcelain --untracked-files=no
+git -C ${goenv_dir} fetch --quiet --tags origin
+git -C ${goenv_dir} symbolic-ref -q HEAD
+git -C ${goenv_dir} verify-commit @{upstream}
+git -C ${goenv_dir} merge --quiet --ff-only @{upstream}
+OUT
+}
+
+@test "doesn't fast-forward a git checkout to a fetched commit that can't be verified" {
+  install_goenv "${GOENV_TEST_DIR}/goenv"
+  mkdir "${goenv_dir}/.git"
+  cat > "${GOENV_TEST_DIR}/bin/git" <<SH
+#!/bin/sh
+case "\$*" in
+*verify-commit*) echo "error: no signature found" >&2; exit 1 ;;
+*merge*) echo "git \$*" >> "${GOENV_TEST_DIR}/log"; echo "2.3.1" > "${goenv_dir}/APP_VERSION" ;;
+esac
+SH
+  chmod +x "${GOENV_TEST_DIR}/bin/git"
+
+  run "${goenv_dir}/libexec/goenv-update"
+  assert_failure "goenv: cannot verify the signature of the fetched commit, import the key of the goenv maintainers or update with --no-verify, '${goenv_dir}' was left as it is"
+  assert [ ! -e "${GOENV_TEST_DIR}/log" ]
+
+  run "${goenv_dir}/libexec/goenv-update" --no-verify
+  assert_success
+  assert_line 0 "goenv: WARNING: not verifying the signature of the fetched commit because of --no-verify, it may not come from go-nv/goenv"
+  assert_equal "git -C ${goenv_dir} merge --quiet --ff-only @{upstream}" "$(cat "${GOENV_TEST_DIR}/log")"
+  run cat "${GOENV_ROOT}/audit.log"
+  assert_success
+  [[ "$output" =~ ^[0-9T:-]+Z\ update\ 2\.2\.0\ to\ 2\.3\.0\ unverified\ the\ signature\ of\ the\ fetched\ commit\ by\  ]]
+}
+
+@test "checks out the latest release of a git checkout of a release" {
+  install_goenv "${GOENV_TEST_DIR}/goenv"
+  mkdir "${goenv_dir}/.git"
+  cat > "${GOENV_TEST_DIR}/bin/git" <<SH
+#!/bin/sh
+[ "\$3" != "symbolic-ref" ] || exit 1
+case "\$*" in
+*verify-tag*) echo "git \$*" >> "${GOENV_TEST_DIR}/log" ;;
+*checkout*) echo "git \$*" >> "${GOENV_TEST_DIR}/log"; echo "2.3.0" > "${goenv_dir}/APP_VERSION" ;;
+esac
+SH
+  chmod +x "${GOENV_TEST_DIR}/bin/git"
+
+  run "${goenv_dir}/libexec/goenv-update"
+  assert_success "goenv: updated from 2.2.0 to 2.3.0"
+  run cat "${GOENV_TEST_DIR}/log"
+  assert_success_out <<OUT
+git -C ${goenv_dir} verify-tag v2.3.0
+git -C ${goenv_dir} -c advice.detachedHead=false checkout --quiet v2.3.0
+OUT
+}
+
+@test "doesn't check out a release whose tag can't be verified" {
+  install_goenv "${GOENV_TEST_DIR}/goenv"
+  mkdir "${goenv_dir}/.git"
+  cat > "${GOENV_TEST_DIR}/bin/git" <<SH
+#!/bin/sh
+[ "\$3" != "symbolic-ref" ] || exit 1
+case "\$*" in
+*verify-tag*) echo "error: no signature found" >&2; exit 1 ;;
+*checkout*) echo "git \$*" >> "${GOENV_TEST_DIR}/log" ;;
+esac
+SH
+  chmod +x "${GOENV_TEST_DIR}/bin/git"
+
+  run "${goenv_dir}/libexec/goenv-update"
+  assert_failure "goenv: cannot verify the signature of the tag v2.3.0, import the key of the goenv maintainers or update with --no-verify, '${goenv_dir}' was left as it is"
+  assert [ ! -e "${GOENV_TEST_DIR}/log" ]
+
+  run "${goenv_dir}/libexec/goenv-update" --no-verify
+  assert_success
+  assert_line 0 "goenv: WARNING: not verifying the signature of the tag v2.3.0 because of --no-verify, it may not come from go-nv/goenv"
+  assert_equal "git -C ${goenv_dir} -c advice.detachedHead=false checkout --quiet v2.3.0" "$(cat "${GOENV_TEST_DIR}/log")"
+  run cat "${GOENV_ROOT}/audit.log"
+  assert_success
+  [[ "$output" =~ ^[0-9T:-]+Z\ update\ 2\.2\.0\ to\ 2\.3\.0\ unverified\ the\ signature\ of\ the\ tag\ v2\.3\.0\ by\  ]]
+}
+
+@test "doesn't update a git checkout with local changes" {
+  install_goenv "${GOENV_TEST_DIR}/goenv"
+  mkdir "${goenv_dir}/.git"
+  cat > "${GOENV_TEST_DIR}/bin/git" <<SH
+#!/bin/sh
+[ "\$3" != "status" ] || echo " M libexec/goenv"
+SH
+  chmod +x "${GOENV_TEST_DIR}/bin/git"
+
+  run "${goenv_dir}/libexec/goenv-update"
+  assert_failure "goenv: cannot update '${goenv_dir}', it has local changes"
+}
+
+@test "replaces goenv installed from a release archive with the latest one" {
+  install_goenv "${GOENV_TEST_DIR}/goenv"
+  release_archive 2.3.0
+  stub_gh 0
+
+  run "${goenv_dir}/libexec/goenv-update"
+  assert_success "goenv: updated from 2.2.0 to 2.3.0"
+  assert_equal "2.3.0" "$(cat "${goenv_dir}/APP_VERSION")"
+  assert [ -x "${goenv_dir}/libexec/goenv-update" ]
+  run ls -d "${goenv_dir}".update.*
+  assert_failure
+}
+
+@test "doesn't replace goenv with a release archive that doesn't match its checksum" {
+  install_goenv "${GOENV_TEST_DIR}/goenv"
+  release_archive 2.3.0
+  echo "corrupted" >> "${GOENV_TEST_DIR}/release/goenv-2.3.0.tar.gz"
+  stub_gh 0
+
+  run "${goenv_dir}/libexec/goenv-update"
+  assert_failure
+  [[ "$output" == *"doesn't match SHA256SUMS of the 2.3.0 release"* ]]
+  assert_equal "2.2.0" "$(cat "${goenv_dir}/APP_VERSION")"
+  run ls -d "${goenv_dir}".update.*
+  assert_failure
+}
+
+@test "doesn't replace goenv with a release archive whose attestation doesn't verify" {
+  install_goenv "${GOENV_TEST_DIR}/goenv"
+  release_archive 2.3.0
+  stub_gh 1
+
+  run "${goenv_dir}/libexec/goenv-update"
+  assert_failure "goenv: the attestation of goenv-2.3.0.tar.gz doesn't prove it was built from go-nv/goenv, '${goenv_dir}' was left as it is"
+  assert_equal "2.2.0" "$(cat "${goenv_dir}/APP_VERSION")"
+  run cat "${GOENV_TEST_DIR}/log"
+  assert_success
+  [[ "$output" == "gh attestation verify ${goenv_dir}.update."*"/goenv-2.3.0.tar.gz --repo go-nv/goenv" ]]
+}
+
+@test "replaces goenv with a release archive without verifying its attestation with --no-verify" {
+  install_goenv "${GOENV_TEST_DIR}/goenv"
+  release_archive 2.3.0
+  stub_gh 1
+
+  run "${goenv_dir}/libexec/goenv-update" --no-verify
+  assert_success_out <<OUT
+goenv: WARNING: not verifying the attestation of goenv-2.3.0.tar.gz because of --no-verify, it may not come from go-nv/goenv
+goenv: updated from 2.2.0 to 2.3.0
+OUT
+  assert_equal "2.3.0" "$(cat "${goenv_dir}/APP_VERSION")"
+  assert [ ! -e "${GOENV_TEST_DIR}/log" ]
+  run cat "${GOENV_ROOT}/audit.log"
+  assert_success
+  [[ "$output" =~ ^[0-9T:-]+Z\ update\ 2\.2\.0\ to\ 2\.3\.0\ unverified\ the\ attestation\ of\ goenv-2\.3\.0\.tar\.gz\ by\  ]]
+}
+
+@test "tells where to download the latest release when it has no archive" {
+  install_goenv "${GOENV_TEST_DIR}/goenv"
+
+  run "${goenv_dir}/libexec/goenv-update"
+  assert_failure "goenv: the 2.3.0 release has no goenv-2.3.0.tar.gz and SHA256SUMS to replace '${goenv_dir}' with, download https://github.com/go-nv/goenv/releases/tag/v2.3.0 and replace it"
+}
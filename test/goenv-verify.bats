@@ -0,0 +1,188 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+@test "has usage instructions" {
+  run goenv-help --usage verify
+  assert_success_out <<OUT
+Usage: goenv verify [<version>|--all] [--json]
+       goenv verify --schedule daily|weekly|off
+       goenv verify --record <version>
+OUT
+}
+
+@test "has completion support" {
+  run goenv-verify --complete
+  assert_success_out <<OUT
+--all
+--json
+--schedule
+--record
+OUT
+}
+
+@test "fails and prints usage when given a version and --all" {
+  run goenv-verify 1.22.5 --all
+  assert_failure
+  assert_line 0 "Usage: goenv verify [<version>|--all] [--json]"
+}
+
+@test "verifies the selected version when no version is given" {
+  create_executable "1.22.5" "go"
+  create_executable "1.21.0" "go"
+  goenv-verify --record 1.22.5
+  echo "1.22.5" > "${GOENV_ROOT}/version"
+
+  run goenv-verify
+
+  assert_success "1.22.5: ok"
+}
+
+@test "verifies the given version only" {
+  create_executable "1.22.5" "go"
+  create_executable "1.21.0" "go"
+
+  run goenv-verify 1.21.0
+
+  assert_success "1.21.0: recorded (no manifest yet)"
+  assert [ ! -e "${GOENV_ROOT}/manifests/1.22.5" ]
+}
+
+@test "fails when the version to verify isn't installed" {
+  run goenv-verify 1.22.5
+  assert_failure "goenv: version '1.22.5' not installed"
+}
+
+@test "records a manifest on the first verification" {
+  create_executable "1.22.5" "go"
+
+  run goenv-verify --all
+
+  assert_success "1.22.5: recorded (no manifest yet)"
+  assert [ -s "${GOENV_ROOT}/manifests/1.22.5" ]
+  [[ "$(cat "${GOENV_ROOT}/audit.log")" == *" 1.22.5 recorded" ]]
+}
+
+@test "reports nothing changed when the files match the manifest" {
+  create_executable "1.22.5" "go"
+  goenv-verify --record 1.22.5
+
+  run goenv-verify --all
+
+  assert_success "1.22.5: ok"
+}
+
+@test "leaves the env file of a version out of its checksums" {
+  create_executable "1.22.5" "go"
+  goenv-verify --record 1.22.5
+  echo "GODEBUG=httpmuxgo121=1" > "${GOENV_ROOT}/versions/1.22.5/env"
+
+  run goenv-verify --all
+  assert_success "1.22.5: ok"
+
+  echo "CGO_ENABLED=0" >> "${GOENV_ROOT}/versions/1.22.5/env"
+  goenv-verify --record 1.22.5
+  rm "${GOENV_ROOT}/versions/1.22.5/env"
+
+  run goenv-verify --all
+  assert_success "1.22.5: ok"
+}
+
+@test "fails and logs the drift when files changed" {
+  create_executable "1.22.5" "go"
+  create_executable "1.22.5" "gofmt"
+  goenv-verify --record 1.22.5
+  echo "tampered" >> "${GOENV_ROOT}/versions/1.22.5/bin/go"
+  rm "${GOENV_ROOT}/versions/1.22.5/bin/gofmt"
+
+  run goenv-verify --all
+
+  assert_failure
+  assert_line 0 "1.22.5: changed bin/go"
+  assert_line 1 "1.22.5: missing bin/gofmt"
+  [[ "$(cat "${GOENV_ROOT}/audit.log")" == *" 1.22.5 drift changed bin/go"* ]]
+}
+
+@test "adds new tools to the manifest but reports changed ones" {
+  create_executable "1.22.5" "go"
+  goenv-verify --record 1.22.5
+  create_executable "${HOME}/go/1.22.5/bin" "gopls"
+
+  run goenv-verify --all
+  assert_success "1.22.5: ok"
+
+  echo "rebuilt" >> "${HOME}/go/1.22.5/bin/gopls"
+  run goenv-verify --all
+  assert_failure "1.22.5: changed tool gopls"
+}
+
+@test "schedules verification with cron" {
+  create_executable "${GOENV_TEST_DIR}/bin" "crontab" <<SH
+#!$BASH
+if [ "\$1" = "-l" ]; then
+  echo "0 1 * * * backup"
+else
+  cat > "${GOENV_TEST_DIR}/crontab"
+fi
+SH
+
+  run goenv-verify --all --schedule weekly
+
+  assert_success "goenv: scheduled 'goenv verify --all' to run weekly, results go to ${GOENV_ROOT}/audit.log"
+  run cat "${GOENV_TEST_DIR}/crontab"
+  assert_line 0 "0 1 * * * backup"
+  assert_line 1 "0 3 * * 0 GOENV_ROOT='${GOENV_ROOT}' '$(command -v goenv)' verify --all >/dev/null 2>&1 # goenv verify"
+}
+
+@test "checks the recorded archive against its official checksum" {
+  create_executable "1.22.5" "go"
+  goenv-verify --record 1.22.5
+  mkdir -p "${GOENV_TEST_DIR}/dl"
+  echo "4e7c8a0e1b6e3d1c2a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1f0e9d8c7b" > "${GOENV_TEST_DIR}/dl/go1.22.5.linux-amd64.tar.gz.sha256"
+  cat > "${GOENV_ROOT}/versions/1.22.5/.goenv-metadata" <<META
+archive_url=file://${GOENV_TEST_DIR}/dl/go1.22.5.linux-amd64.tar.gz
+archive_sha256=4e7c8a0e1b6e3d1c2a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1f0e9d8c7b
+META
+  goenv-verify --record 1.22.5
+
+  run goenv-verify 1.22.5
+  assert_success "1.22.5: ok"
+  [[ "$(cat "${GOENV_ROOT}/audit.log")" == *" 1.22.5 archive ok go1.22.5.linux-amd64.tar.gz"* ]]
+
+  echo "0000000000000000000000000000000000000000000000000000000000000000" > "${GOENV_TEST_DIR}/dl/go1.22.5.linux-amd64.tar.gz.sha256"
+  run goenv-verify 1.22.5
+  assert_failure "1.22.5: archive go1.22.5.linux-amd64.tar.gz doesn't match its official checksum"
+}
+
+@test "skips the archive check when the official checksum can't be downloaded" {
+  create_executable "1.22.5" "go"
+  cat > "${GOENV_ROOT}/versions/1.22.5/.goenv-metadata" <<META
+archive_url=file://${GOENV_TEST_DIR}/missing/go1.22.5.linux-amd64.tar.gz
+archive_sha256=4e7c8a0e1b6e3d1c2a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1f0e9d8c7b
+META
+  goenv-verify --record 1.22.5
+
+  run goenv-verify 1.22.5
+
+  assert_success
+  assert_line 0 "1.22.5: archive not checked, cannot download the official checksum of go1.22.5.linux-amd64.tar.gz"
+  assert_line 1 "1.22.5: ok"
+}
+
+@test "prints the results as JSON" {
+  create_executable "1.22.5" "go"
+  create_executable "1.21.0" "go"
+  goenv-verify --record 1.22.5
+  goenv-verify --record 1.21.0
+  echo "tampered" >> "${GOENV_ROOT}/versions/1.22.5/bin/go"
+
+  run goenv-verify --all --json
+
+  assert_failure
+  assert_output <<OUT
+[
+  {"version": "1.21.0", "status": "ok", "archive": null, "problems": []},
+  {"version": "1.22.5", "status": "failed", "archive": null, "problems": ["changed bin/go"]}
+]
+OUT
+}
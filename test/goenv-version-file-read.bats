@@ -109,3 +109,35 @@ IN
   run goenv-version-file-read my-version
   assert_success "1.11.1"
 }
+
+@test "reads a fallback list separated by '||' as a single version" {
+  echo "1.24rc1 || 1.23.x" >my-version
+
+  run goenv-version-file-read my-version
+  assert_success "1.24rc1||1.23.x"
+}
+
+@test "reads lines starting or ending with '||' as fallbacks, and ranges as one word" {
+  cat >my-version <<IN
+# Newest supported release
+>=1.22  <1.24   # for the library
+  || 1.21.x
+IN
+
+  run goenv-version-file-read my-version
+  assert_success ">=1.22,<1.24||1.21.x"
+
+  printf '1.24rc1 ||\n1.23.x\n1.22.5\n' >my-version
+  run goenv-version-file-read my-version
+  assert_success "1.24rc1||1.23.x:1.22.5"
+}
+
+@test "reads several versions one per line, separated by ':'" {
+  cat >my-version <<IN
+1.11.1
+1.10.3
+IN
+
+  run goenv-version-file-read my-version
+  assert_success "1.11.1:1.10.3"
+}
@@ -10,17 +10,17 @@ setup() {
 @test "has usage instructions" {
   run goenv-help --usage version-file-write
   assert_success_out <<OUT
-Usage: goenv version-file-write <file> <version>...
+Usage: goenv version-file-write [--yes] <file> <version>...
 OUT
 }
 
 @test "prints usage instructions when 2 arguments aren't specified" {
   run goenv-version-file-write
 
-  assert_failure "Usage: goenv version-file-write <file> <version>..."
+  assert_failure "Usage: goenv version-file-write [--yes] <file> <version>..."
 
   run goenv-version-file-write "one"
-  assert_failure "Usage: goenv version-file-write <file> <version>..."
+  assert_failure "Usage: goenv version-file-write [--yes] <file> <version>..."
 }
 
 @test "fails when 2 arguments are specified, but version is non-existent" {
@@ -42,6 +42,15 @@ OUT
   assert [ "$(cat my-version)" = "1.11.1" ]
 }
 
+@test "writes several versions one per line" {
+  mkdir -p "${GOENV_ROOT}/versions/1.11.1" "${GOENV_ROOT}/versions/1.10.3"
+
+  run goenv-version-file-write "${PWD}/my-version" "1.11.1" "1.10.3"
+
+  assert_success ""
+  assert [ "$(cat my-version)" = $'1.11.1\n1.10.3' ]
+}
+
 @test "remove local version when 'system' version is given and any local version is installed" {
   mkdir -p "${GOENV_ROOT}/versions/1.2.3"
   run goenv-local latest
@@ -91,3 +100,23 @@ OUT
   assert_success "system"
 }
 
+
+@test "warns about switching to the latest version when it is a release candidate of a much older one" {
+  mkdir -p "${GOENV_ROOT}/versions/1.20rc1"
+  echo "1.22.5" > .go-version
+
+  run goenv-version-file-write .go-version latest < /dev/null
+
+  assert_success "goenv: switching from 1.22.5 to the much older 1.20rc1"
+  assert [ "$(cat .go-version)" = "1.20rc1" ]
+}
+
+@test "switches to the latest version without asking when it is a beta of a newer one" {
+  mkdir -p "${GOENV_ROOT}/versions/1.24beta1"
+  echo "1.23.4" > .go-version
+
+  run goenv-version-file-write .go-version latest < /dev/null
+
+  assert_success ""
+  assert [ "$(cat .go-version)" = "1.24beta1" ]
+}
@@ -121,3 +121,128 @@ OUT
 
   assert_success "1.10.3"
 }
+
+@test "reports queued version that is not yet installed" {
+  mkdir -p "$GOENV_ROOT"
+  echo 1.6.1 > "${GOENV_ROOT}/queue"
+
+  GOENV_VERSION=1.6.1 run goenv-version-name
+
+  assert_failure "goenv: version '1.6.1' is queued but not yet installed, run 'goenv queue run' (set by GOENV_VERSION environment variable)"
+}
+
+@test "prints the first installed version of a fallback list separated by '||'" {
+  create_version "1.22.5"
+  create_version "1.23.4"
+
+  GOENV_VERSION="1.24rc1||1.23.x||1.22.5" run goenv-version-name
+  assert_success "1.23.4"
+}
+
+@test "fails when none of the versions of a fallback list separated by '||' is installed" {
+  GOENV_VERSION="1.24rc1||1.23.x" run goenv-version-name
+  assert_failure "goenv: none of the versions '1.24rc1 || 1.23.x' is installed (set by GOENV_VERSION environment variable)"
+}
+
+@test "prints the newest installed stable version of a range" {
+  create_version "1.21.5"
+  create_version "1.22.3"
+  create_version "1.23.4"
+  create_version "1.24rc1"
+  create_version "1.24.0"
+
+  GOENV_VERSION=">=1.22 <1.24" run goenv-version-name
+  assert_success "1.23.4"
+
+  GOENV_VERSION=">=1.25||>1.21,<=1.22.3" run goenv-version-name
+  assert_success "1.22.3"
+}
+
+@test "fails naming the versions that match a range when none is installed" {
+  create_version "1.21.5"
+  create_executable "${GOENV_TEST_DIR}/bin" "goenv-install" <<SH
+#!/bin/sh
+printf 'Available versions:\\n  1.21.5\\n  1.22.0\\n  1.22.10\\n  1.23.3\\n  1.23.4\\n  1.24rc1\\n'
+SH
+
+  GOENV_VERSION=">=1.22 <1.24" run goenv-version-name
+  assert_failure
+  assert_output <<OUT
+goenv: no installed version matches '>=1.22 <1.24' (set by GOENV_VERSION environment variable)
+goenv: install one of the versions that match: 1.23.4, 1.23.3, 1.22.10
+OUT
+}
+
+@test "caches the version of a directory until a file it was selected by changes" {
+  create_version "1.22.5"
+  create_version "1.23.1"
+  echo "1.22.5" > .go-version
+
+  run goenv-version-name
+  assert_success "1.22.5"
+  assert [ -f "${GOENV_ROOT}/.cache/version-name/${UID}/${PWD//\//%2F}" ]
+  assert_equal "drwx------" "$(ls -ld "${GOENV_ROOT}/.cache/version-name/${UID}" | cut -c1-10)"
+
+  # NOTE: Keep the modification time, so that the entry is still used.
+  echo "1.23.1" > .go-version
+  touch -r "${GOENV_ROOT}/versions" .go-version
+  run goenv-version-name
+  assert_success "1.22.5"
+
+  echo "1.23.1" > .go-version
+  run goenv-version-name
+  assert_success "1.23.1"
+}
+
+@test "keeps the cached versions of directories with % in their names apart" {
+  create_version "1.22.5"
+  create_version "1.23.1"
+  mkdir -p "${GOENV_TEST_DIR}/project/a" "${GOENV_TEST_DIR}/project%a"
+  echo "1.22.5" > "${GOENV_TEST_DIR}/project/a/.go-version"
+  echo "1.23.1" > "${GOENV_TEST_DIR}/project%a/.go-version"
+
+  cd "${GOENV_TEST_DIR}/project/a"
+  run goenv-version-name
+  assert_success "1.22.5"
+
+  cd "${GOENV_TEST_DIR}/project%a"
+  run goenv-version-name
+  assert_success "1.23.1"
+}
+
+@test "doesn't use a cached version older than GOENV_VERSION_CACHE_TTL" {
+  create_version "1.22.5"
+  create_version "1.23.1"
+  echo "1.22.5" > .go-version
+  goenv-version-name
+  echo "1.23.1" > .go-version
+  touch -r "${GOENV_ROOT}/versions" .go-version
+  entry="${GOENV_ROOT}/.cache/version-name/${UID}/${PWD//\//%2F}"
+  sed '2s/.*/0/' "$entry" > "${entry}.new"
+  mv "${entry}.new" "$entry"
+
+  run goenv-version-name
+  assert_success "1.23.1"
+}
+
+@test "doesn't cache the version when GOENV_VERSION_CACHE_TTL is 0" {
+  create_version "1.22.5"
+  echo "1.22.5" > .go-version
+
+  GOENV_VERSION_CACHE_TTL=0 run goenv-version-name
+
+  assert_success "1.22.5"
+  assert [ ! -e "${GOENV_ROOT}/.cache/version-name" ]
+}
+
+@test "forgets the cached versions when a version is selected" {
+  create_version "1.22.5"
+  create_version "1.23.1"
+  echo "1.22.5" > "${GOENV_ROOT}/version"
+  goenv-version-name
+
+  run goenv-version-file-write "${GOENV_ROOT}/version" 1.23.1
+
+  assert_success
+  assert [ ! -e "${GOENV_ROOT}/.cache/version-name/${UID}" ]
+}
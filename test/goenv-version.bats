@@ -10,7 +10,7 @@ setup() {
 @test "has usage instructions" {
   run goenv-help --usage version
   assert_success_out <<OUT
-Usage: goenv version
+Usage: goenv version [--explain|--origin-chain]
 OUT
 }
 
@@ -81,3 +81,78 @@ goenv: version '1.2' is not installed (set by GOENV_VERSION environment variable
 OUT
   unset GOENV_GOMOD_VERSION_ENABLE
 }
+
+@test "uses the first installed version of a fallback list from '.go-version' local file" {
+  create_version "1.23.2"
+  create_version "1.23.4"
+  echo "1.24rc1 || 1.23.x" >'.go-version'
+
+  run goenv-version
+  assert_success "1.23.4 (set by ${PWD}/.go-version)"
+}
+
+@test "explains how a fallback list from '.go-version' local file was resolved when '--explain' is given" {
+  create_version "1.23.2"
+  create_version "1.23.4"
+  echo "1.24rc1 || 1.23.x || 1.23.2" >'.go-version'
+
+  run goenv-version --explain
+  assert_success_out <<OUT
+1.24rc1 || 1.23.x || 1.23.2 (set by ${PWD}/.go-version)
+  1. 1.24rc1: not installed
+  2. 1.23.x: installed as 1.23.4, selected
+  3. 1.23.2: skipped
+OUT
+}
+
+@test "fails explaining a fallback list when none of its versions is installed" {
+  echo "1.24rc1 || 1.23.x" >'.go-version'
+
+  run goenv-version --explain
+  assert_failure_out <<OUT
+1.24rc1 || 1.23.x (set by ${PWD}/.go-version)
+  1. 1.24rc1: not installed
+  2. 1.23.x: not installed
+OUT
+}
+
+@test "lists where a version can be set and which one set it when '--origin-chain' is given" {
+  create_version "1.22.5"
+  mkdir -p app/api
+  echo "1.21.13" > app/.go-version
+  echo "module app" > app/go.mod
+  echo "1.22.5" > app/api/.go-version
+  echo "1.23.1" > "${GOENV_ROOT}/version"
+  cd app/api
+
+  run goenv-version --origin-chain
+  assert_success_out <<OUT
+1.22.5 (set by ${PWD}/.go-version)
+  1. GOENV_VERSION environment variable: not set
+  2. ${PWD}/.go-version: 1.22.5, selected
+  3. ${GOENV_TEST_DIR}/app/.go-version: 1.21.13, skipped
+  4. ${GOENV_TEST_DIR}/app/go.mod: ignored, GOENV_GOMOD_VERSION_ENABLE isn't 1
+  5. ${GOENV_ROOT}/version: 1.23.1, skipped
+  6. system: the Go found in PATH, skipped
+OUT
+}
+
+@test "lists the 'GOENV_VERSION' environment variable first in the origin chain" {
+  GOENV_VERSION="1.22.5" run goenv-version --origin-chain
+  assert_success_out <<OUT
+1.22.5 (set by GOENV_VERSION environment variable)
+  1. GOENV_VERSION environment variable: 1.22.5, selected
+  2. ${GOENV_ROOT}/version: not found
+  3. system: the Go found in PATH, skipped
+OUT
+}
+
+@test "selects system in the origin chain when no version is set" {
+  run goenv-version --origin-chain
+  assert_success_out <<OUT
+system (set by ${GOENV_ROOT}/version)
+  1. GOENV_VERSION environment variable: not set
+  2. ${GOENV_ROOT}/version: not found
+  3. system: the Go found in PATH, selected
+OUT
+}
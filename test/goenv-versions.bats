@@ -16,7 +16,7 @@ stub_system_go() {
 @test "has usage instructions" {
   run goenv-help --usage versions
   assert_success_out <<OUT
-Usage: goenv versions [--bare] [--skip-aliases]
+Usage: goenv versions [--bare] [--skip-aliases] [--verify [--std]]
 OUT
 }
 
@@ -25,13 +25,15 @@ OUT
   assert_success_out <<OUT
 --bare
 --skip-aliases
+--verify
+--std
 OUT
 }
 
 @test "prints usage instructions when unknown arguments are given" {
   run goenv-versions magic and more
   assert_failure_out <<OUT
-Usage: goenv versions [--bare] [--skip-aliases]
+Usage: goenv versions [--bare] [--skip-aliases] [--verify [--std]]
 OUT
 }
 
@@ -178,3 +180,56 @@ OUT
   1.8.3
 OUT
 }
+
+# Creates a version with a fake `go' that counts how often it runs.
+create_go() {
+  mkdir -p "${GOENV_ROOT}/versions/${1}/src/runtime"
+  create_executable "$1" "go" <<SH
+#!/bin/sh
+echo "\$*" >> "${GOENV_TEST_DIR}/go.log"
+[ "\$1" = "env" ] && echo "\$GOROOT"
+exit 0
+SH
+}
+
+@test "marks healthy and broken versions with '--verify'" {
+  create_go "1.22.5"
+  create_go "1.21.0"
+  rm -r "${GOENV_ROOT}/versions/1.21.0/src"
+  mkdir -p "${GOENV_ROOT}/versions/1.20.0"
+
+  run goenv-versions --bare --verify
+  assert_failure_out <<OUT
+1.20.0 (broken: bin/go is missing)
+1.21.0 (broken: the standard library is missing)
+1.22.5 (ok)
+OUT
+}
+
+@test "reuses the results of '--verify' until the version changes" {
+  create_go "1.22.5"
+
+  run goenv-versions --bare --verify
+  assert_success "1.22.5 (ok)"
+  run goenv-versions --bare --verify
+  assert_success "1.22.5 (ok)"
+  assert_equal 2 "$(wc -l < "${GOENV_TEST_DIR}/go.log" | tr -d ' ')"
+
+  sleep 1
+  touch "${GOENV_ROOT}/versions/1.22.5/bin/go"
+  run goenv-versions --bare --verify
+  assert_success "1.22.5 (ok)"
+  assert_equal 4 "$(wc -l < "${GOENV_TEST_DIR}/go.log" | tr -d ' ')"
+}
+
+@test "also compiles the standard library with '--std'" {
+  create_go "1.22.5"
+
+  run goenv-versions --bare --verify --std
+  assert_success "1.22.5 (ok)"
+  run grep -x "build std" "${GOENV_TEST_DIR}/go.log"
+  assert_success
+
+  run goenv-versions --std
+  assert_failure
+}
@@ -0,0 +1,91 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  export GOENV_WATCH_INTERVAL=0.1
+}
+
+teardown() {
+  goenv-watch stop >/dev/null 2>&1 || true
+}
+
+# Waits for a file to appear or disappear, as the watcher rehashes in the
+# background.
+wait_for() {
+  local i
+  for i in $(seq 50); do
+    ! test "$@" || return 0
+    sleep 0.1
+  done
+  return 1
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage watch
+  assert_success "Usage: goenv watch start|stop|status"
+}
+
+@test "fails and prints usage when no subcommand is given" {
+  run goenv-watch
+  assert_failure "Usage: goenv watch start|stop|status"
+}
+
+@test "has completion support" {
+  run goenv-watch --complete
+  assert_success_out <<OUT
+start
+stop
+status
+OUT
+}
+
+@test "starts, reports and stops the watcher" {
+  mkdir -p "${GOENV_ROOT}/versions"
+
+  run goenv-watch status
+  assert_failure "watch is not running"
+
+  run goenv-watch start
+  assert_success
+  pid="$(cat "${GOENV_ROOT}/watch/pid")"
+  assert_equal "goenv: watch started (pid ${pid})" "$output"
+
+  run goenv-watch status
+  assert_success "watch is running (pid ${pid})"
+
+  run goenv-watch start
+  assert_failure "goenv: watch is already running (pid ${pid})"
+
+  run goenv-watch stop
+  assert_success "goenv: watch stopped"
+
+  run goenv-watch stop
+  assert_failure "goenv: watch is not running"
+}
+
+@test "rehashes when executables are added or removed" {
+  create_executable "1.22.5" "go"
+  goenv-rehash
+  goenv-watch start >/dev/null
+
+  mkdir -p "${HOME}/go/1.22.5/bin"
+  create_executable "${HOME}/go/1.22.5/bin" "gopls"
+  wait_for -x "${GOENV_ROOT}/shims/gopls"
+
+  rm "${HOME}/go/1.22.5/bin/gopls"
+  wait_for ! -e "${GOENV_ROOT}/shims/gopls"
+
+  run goenv-logs show watch
+  assert_success
+  [[ "${lines[0]}" == *" goenv: added shim(s): gopls" ]]
+  [[ "${lines[1]}" == *" goenv: removed shim(s): gopls" ]]
+}
+
+@test "rehashes when a version is installed" {
+  mkdir -p "${GOENV_ROOT}/versions"
+  goenv-watch start >/dev/null
+
+  create_executable "1.22.5" "go"
+  wait_for -x "${GOENV_ROOT}/shims/go"
+}
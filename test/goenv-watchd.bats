@@ -0,0 +1,107 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+setup() {
+  mkdir -p "${GOENV_ROOT}/versions/1.22.5" "${GOENV_ROOT}/versions/1.23.0"
+  mkdir -p "${GOENV_TEST_DIR}/app"
+  echo "1.22.5" > "${GOENV_TEST_DIR}/app/.go-version"
+  active="${GOENV_ROOT}/watchd/active/${GOENV_TEST_DIR//\//_}_app"
+}
+
+teardown() {
+  goenv-watchd stop >/dev/null 2>&1 || true
+}
+
+@test "has usage instructions" {
+  run goenv-help --usage watchd
+  assert_success_out <<OUT
+Usage: goenv watchd add|remove <dir>
+       goenv watchd list
+       goenv watchd start|stop|status
+       goenv watchd sync
+OUT
+}
+
+@test "fails and prints usage when no subcommand is given" {
+  run goenv-watchd
+  assert_failure
+  assert_line 0 "Usage: goenv watchd add|remove <dir>"
+}
+
+@test "fails to add a directory that doesn't exist" {
+  run goenv-watchd add "${GOENV_TEST_DIR}/nope"
+  assert_failure "goenv: '${GOENV_TEST_DIR}/nope' is not a directory"
+}
+
+@test "links and writes an env file for a project when it's added" {
+  run goenv-watchd add "${GOENV_TEST_DIR}/app"
+  assert_success ""
+
+  assert_equal "${GOENV_ROOT}/versions/1.22.5" "$(readlink "$active")"
+  run cat "${active}.env"
+  assert_success_out <<OUT
+GOROOT=${GOENV_ROOT}/versions/1.22.5
+GOPATH=${HOME}/go/1.22.5
+PATH=${GOENV_ROOT}/versions/1.22.5/bin:${HOME}/go/1.22.5/bin:\${PATH}
+OUT
+
+  run goenv-watchd list
+  assert_success "${GOENV_TEST_DIR}/app -> 1.22.5 (${active})"
+}
+
+@test "follows changes to '.go-version' on sync" {
+  goenv-watchd add "${GOENV_TEST_DIR}/app"
+  echo "1.23.0" > "${GOENV_TEST_DIR}/app/.go-version"
+
+  run goenv-watchd sync
+  assert_success ""
+  assert_equal "${GOENV_ROOT}/versions/1.23.0" "$(readlink "$active")"
+}
+
+@test "removes the link when no installed version is selected" {
+  goenv-watchd add "${GOENV_TEST_DIR}/app"
+  echo "1.99.0" > "${GOENV_TEST_DIR}/app/.go-version"
+
+  run goenv-watchd sync
+  assert_success ""
+  assert [ ! -e "$active" ]
+
+  run goenv-watchd list
+  assert_success "${GOENV_TEST_DIR}/app -> (none)"
+}
+
+@test "removes a project" {
+  goenv-watchd add "${GOENV_TEST_DIR}/app"
+
+  run goenv-watchd remove "${GOENV_TEST_DIR}/app"
+  assert_success ""
+  assert [ ! -e "$active" ]
+
+  run goenv-watchd list
+  assert_success ""
+}
+
+@test "starts, reports and stops the watcher" {
+  goenv-watchd add "${GOENV_TEST_DIR}/app"
+
+  run goenv-watchd status
+  assert_failure "watchd is not running, 1 project(s) registered"
+
+  run goenv-watchd start
+  assert_success
+  pid="$(cat "${GOENV_ROOT}/watchd/pid")"
+  assert_equal "goenv: watchd started (pid ${pid})" "$output"
+
+  run goenv-watchd status
+  assert_success "watchd is running (pid ${pid}), watching 1 project(s)"
+
+  run goenv-watchd start
+  assert_failure "goenv: watchd is already running (pid ${pid})"
+
+  run goenv-watchd stop
+  assert_success "goenv: watchd stopped"
+
+  run goenv-watchd stop
+  assert_failure "goenv: watchd is not running"
+}
@@ -68,3 +68,13 @@ OUT
   assert_failure ""
 }
 
+
+@test "fails and suggests 'go tool' for a command declared as a tool in go.mod" {
+  create_executable "1.24.0" "go"
+  mkdir -p "${GOENV_TEST_DIR}/app"
+  cd "${GOENV_TEST_DIR}/app"
+  printf 'module example.com/app\n\ngo 1.24\n\ntool golang.org/x/tools/cmd/stringer\n' > go.mod
+
+  run goenv-whence stringer
+  assert_failure "goenv: 'stringer' is a tool declared in '${GOENV_TEST_DIR}/app/go.mod', run it with 'go tool stringer'"
+}
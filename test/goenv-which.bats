@@ -4,17 +4,17 @@ load test_helper
 
 @test "has usage instructions" {
   run goenv-help --usage which
-  assert_success "Usage: goenv which <command>"
+  assert_success "Usage: goenv which [--all] <command>"
 }
 
 @test "has completion support" {
   run goenv-which --complete
-  assert_success ""
+  assert_success "--all"
 }
 
 @test "fails and prints usage when no command argument is given" {
   run goenv-which
-  assert_failure "Usage: goenv which <command>"
+  assert_failure "Usage: goenv which [--all] <command>"
 }
 
 @test "prints path to executable when 'GOENV_VERSION' environment variable is specified and executable argument is found in 'GOENV_ROOT/versions/<version>/bin/<executable>'" {
@@ -79,6 +79,21 @@ The 'gofmt' command exists in these Go versions:
 OUT
 }
 
+@test "suggests 'go tool' for a command declared as a tool in go.mod" {
+  create_executable "1.24.0" "go"
+  mkdir -p "${GOENV_TEST_DIR}/app/cmd"
+  printf 'module example.com/app\n\ngo 1.24\n\ntool (\n\tgithub.com/99designs/gqlgen/v2 // codegen\n)\n' > "${GOENV_TEST_DIR}/app/go.mod"
+  cd "${GOENV_TEST_DIR}/app/cmd"
+
+  GOENV_VERSION=1.24.0 run -127 goenv-which gqlgen
+  assert_failure_out <<OUT
+goenv: 'gqlgen' command not found
+
+The 'gqlgen' command is a tool declared in '${GOENV_TEST_DIR}/app/go.mod', run it with:
+  go tool gqlgen
+OUT
+}
+
 @test "carries original IFS within hooks" {
   create_hook which hello.bash <<SH
 hellos=(\$(printf "hello\\tugly world\\nagain"))
@@ -124,6 +139,15 @@ SH
   assert_success "${GOENV_TEST_DIR}/bin/kill-all-humans"
 }
 
+@test "prints executable found in PATH for 'system' specified by 'GOENV_VERSION' environment variable excludes 'GOENV_ALLOWED_SHIMS_DIR'" {
+  create_executable "${GOENV_TEST_DIR}/bin" "kill-all-humans"
+  create_executable "${GOENV_ROOT}/shims-allowed/1" "kill-all-humans"
+
+  PATH="${GOENV_ROOT}/shims-allowed/1:$PATH" GOENV_ALLOWED_SHIMS_DIR="${GOENV_ROOT}/shims-allowed/1" \
+    GOENV_VERSION=system run goenv-which kill-all-humans
+  assert_success "${GOENV_TEST_DIR}/bin/kill-all-humans"
+}
+
 @test "fails when executable is not found in PATH for 'system' specified by 'GOENV_VERSION' even though it's present in current dir" {
   export PATH="$(path_without "kill-all-humans")"
   mkdir -p "$GOENV_TEST_DIR"
@@ -135,3 +159,28 @@ SH
   assert_failure "goenv: 'kill-all-humans' command not found"
 }
 
+
+@test "lists the command of every version and the system with '--all', marking the one that runs" {
+  create_executable "1.21.13" "golangci-lint"
+  create_executable "1.22.5" "go"
+  create_executable "${HOME}/go/1.22.5/bin" "golangci-lint"
+  create_executable "${GOENV_TEST_DIR}/bin" "golangci-lint"
+  create_executable "${GOENV_ROOT}/shims" "golangci-lint"
+
+  PATH="${GOENV_ROOT}/shims:$PATH" GOENV_VERSION=1.22.5 run goenv-which --all golangci-lint
+
+  assert_success_out <<OUT
+  1.21.13 ${GOENV_ROOT}/versions/1.21.13/bin/golangci-lint
+* 1.22.5  ${HOME}/go/1.22.5/bin/golangci-lint (set by GOENV_VERSION environment variable)
+  system  ${GOENV_TEST_DIR}/bin/golangci-lint
+OUT
+}
+
+@test "fails with '--all' when no version has the command" {
+  create_executable "1.22.5" "go"
+
+  GOENV_VERSION=1.22.5 run goenv-which --all golangci-lint
+
+  assert_failure
+  assert_line 0 "goenv: 'golangci-lint' command not found"
+}
@@ -14,24 +14,57 @@ teardown() {
   assert_success_out <<OUT
 1.10.9
 1.9.10
+atomic-write
+bake
+cache
+cgo-check
 commands
 completions
+doctor
+env
+env-vars
 exec
+export
+first-run
+format
+generate-scope
+gitignore
 global
 help
+hook
 hooks
 init
 install
 installed
 latest
 local
+logs
+migrate
+migrate-arch
+org-defaults
 prefix
+prompt
+prune
+query
+queue
+refresh
 rehash
+repro
 root
+run
+sbom
+setup
 shell
 shims
+stats
+suggest
+sync
 system
+telemetry
+tools
 uninstall
+update
+verify
 version
 version-file
 version-file-read
@@ -39,6 +72,8 @@ version-file-write
 version-name
 version-origin
 versions
+watch
+watchd
 whence
 which
 OUT
@@ -79,24 +114,65 @@ OUT
   
   assert_failure_out <<'OUT'
 Usage: goenv install [-f] [-kvpq] <version>|latest|unstable
+       goenv install [-f] [-kvq] [--jobs <n>] <version> <version>...
        goenv install [-f] [-kvpq] <definition-file>
-       goenv install -l|--list
+       goenv install [-kvpq] gotip [--update]
+       goenv install [-f] [-kvpq] -i|--interactive
+       goenv install --from-file [--prune] <file>
+       goenv install -l|--list [--stable|--unstable|--archived]
        goenv install --version
 
   -l/--list          List all available versions
+  -i/--interactive   Pick the version to install from the available ones,
+                     newest first, see `goenv help prompt'
+  --stable           List only stable releases
+  --unstable         List only betas and release candidates
+  --archived         List only releases of minor versions that reached
+                     their end of life
   -f/--force         Install even if the version appears to be installed already
   -s/--skip-existing Skip if the version appears to be installed already
+  -n/--dry-run       Print the install plan (URL, checksum, target directory,
+                     disk space, tools) without downloading or installing anything
+  --json             Print the install plan as JSON (implies --dry-run)
+  --queue            Queue the version to be installed later by `goenv queue run'
+  --events           Print install events as JSON lines on stdout, e.g. for
+                     GUI front-ends, and everything else on stderr
+  --from-file        Install the versions listed in a `.go-versions' or
+                     `goenv.yaml' file, see `goenv help sync'
+  --jobs <n>         Install up to <n> of several versions at a time
+                     (defaults to `GOENV_INSTALL_JOBS', or 4)
+  --mirror <dir-or-url>
+                     Install from the archives in a directory or at a URL
+                     instead of go.dev (defaults to `GOENV_MIRROR')
+  --require-verification
+                     Fail unless the archive matches a SHA-256 checksum
+                     (defaults to `GOENV_REQUIRE_VERIFICATION')
+  --static-ok        Install an official release on a musl system, e.g.
+                     Alpine, although it's built for glibc
+  --build, --build-from-source
+                     Build the version from source with make.bash, e.g. on
+                     platforms without official releases
+  --update           Update tip to the latest commit, see below
 
   go-build options:
 
   -k/--keep          Keep source tree in $GOENV_BUILD_ROOT after installation
                      (defaults to $GOENV_ROOT/sources)
-  -p/--patch         Apply a patch from stdin before building
+  -p/--patch         Apply a patch from stdin before building, with --build
   -v/--verbose       Verbose mode: print compilation status to stdout
-  -q/--quiet         Disable Progress Bar
+  -q/--quiet         Disable Progress Bar, and print a summary line
+                     of each download instead
   --version          Show version of go-build
   -g/--debug         Build a debug version
 
+`gotip' clones the development branch of Go and builds it with
+make.bash, bootstrapped by the newest installed version, as the version
+`tip'. `goenv install gotip --update' builds the latest commit again.
+
+Under the Go release policy, each minor version receives security fixes
+until two newer minor versions are released. On a terminal, the list
+shows this for the newest release of each minor version.
+
 For detailed information on installing Go versions with
 go-build, including a list of environment variables for adjusting
 compilation, see: https://github.com/go-nv/goenv#readme
@@ -235,3 +311,36 @@ OUT
   assert_failure "goenv: version '1.9' not installed"
 }
 
+
+@test "uses 'GOENV_ROOT/shims' as default 'GOENV_SHIMS_DIR' when 'GOENV_ROOT' is writable" {
+  mkdir -p "${GOENV_ROOT}"
+  run goenv echo GOENV_SHIMS_DIR
+  assert_success "${GOENV_ROOT}/shims"
+}
+
+@test "uses per-user 'GOENV_SHIMS_DIR' when 'GOENV_ROOT' is not writable" {
+  if [ "$(whoami)" = "root" ]; then
+    skip "running as root. permissions won't matter."
+  fi
+
+  mkdir -p "${GOENV_ROOT}"
+  chmod 0555 "${GOENV_ROOT}"
+
+  run goenv echo GOENV_SHIMS_DIR
+
+  chmod 0755 "${GOENV_ROOT}"
+  assert_success "${HOME}/.local/share/goenv/shims"
+}
+
+@test "keeps shims and the offline copy of Go in 'GOENV_LOCAL_DIR' when it is set" {
+  GOENV_LOCAL_DIR=/local/goenv/ run goenv echo GOENV_SHIMS_DIR
+  assert_success "/local/goenv/shims"
+
+  GOENV_LOCAL_DIR=/local/goenv run goenv echo GOENV_FALLBACK_DIR
+  assert_success "/local/goenv/fallback"
+}
+
+@test "uses provided 'GOENV_SHIMS_DIR' without trailing slash when 'GOENV_SHIMS_DIR' environment variable is provided" {
+  GOENV_SHIMS_DIR=/opt/shims/ run goenv echo GOENV_SHIMS_DIR
+  assert_success "/opt/shims"
+}